@@ -16,7 +16,9 @@ type AggregationBuilder struct {
 	query  map[string]interface{}
 	aggs   map[string]interface{}
 	size   int
-	debug  bool // 调试模式标志
+	extra  map[string]interface{} // Extra 设置的顶层键
+	err    error                  // RawQuery、FromJSON 的解析错误
+	debug  bool                   // 调试模式标志
 }
 
 // NewAggregationBuilder 创建聚合构建器
@@ -35,6 +37,52 @@ func (b *AggregationBuilder) Query(query map[string]interface{}) *AggregationBui
 	return b
 }
 
+// RawQuery 使用原始 JSON 设置查询条件
+func (b *AggregationBuilder) RawQuery(raw json.RawMessage) *AggregationBuilder {
+	q, err := rawQuery(raw)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	b.query = q
+	return b
+}
+
+// Extra 设置请求体的顶层键，如 track_total_hits、timeout、runtime_mappings
+// 与构建器生成的同名键（query、size、aggs）冲突时以构建器为准
+func (b *AggregationBuilder) Extra(key string, value interface{}) *AggregationBuilder {
+	b.extra = setExtra(b.extra, key, value)
+	return b
+}
+
+// FromJSON 加载保存的聚合请求体
+// query、size、aggs 写入构建器（之后仍可以继续添加聚合），其余键等同于 Extra
+func (b *AggregationBuilder) FromJSON(data []byte) *AggregationBuilder {
+	body, err := decodeJSONObject(data)
+	if err != nil {
+		b.err = err
+		return b
+	}
+
+	for key, value := range body {
+		switch key {
+		case "query":
+			b.query, err = jsonObject(key, value)
+		case "size":
+			b.size, err = jsonInt(key, value)
+		case "aggs", "aggregations":
+			err = mergeAggs(b.aggs, key, value)
+		default:
+			b.extra = setExtra(b.extra, key, value)
+		}
+		if err != nil {
+			b.err = fmt.Errorf("加载请求体失败: %w", err)
+			return b
+		}
+	}
+	return b
+}
+
 // Size 设置返回文档数量
 func (b *AggregationBuilder) Size(size int) *AggregationBuilder {
 	b.size = size
@@ -394,7 +442,7 @@ func (b *AggregationBuilder) Build() map[string]interface{} {
 		body["query"] = b.query
 	}
 
-	return body
+	return mergeExtra(body, b.extra)
 }
 
 // Debug 启用调试模式（链式调用）
@@ -403,23 +451,6 @@ func (b *AggregationBuilder) Debug() *AggregationBuilder {
 	return b
 }
 
-// printDebug 打印请求调试信息
-func (b *AggregationBuilder) printDebug(method, path string, body interface{}) {
-	fmt.Printf("\n[ES Debug] %s %s\n", method, path)
-	if body != nil {
-		data, _ := json.MarshalIndent(body, "", "  ")
-		fmt.Printf("Request Body:\n%s\n", string(data))
-	}
-}
-
-// printResponse 打印响应调试信息
-func (b *AggregationBuilder) printResponse(respBody []byte) {
-	var pretty interface{}
-	json.Unmarshal(respBody, &pretty)
-	data, _ := json.MarshalIndent(pretty, "", "  ")
-	fmt.Printf("Response:\n%s\n\n", string(data))
-}
-
 // resetDebug 执行后重置debug标志（让每次调用可以独立控制）
 func (b *AggregationBuilder) resetDebug() {
 	b.debug = false
@@ -427,12 +458,16 @@ func (b *AggregationBuilder) resetDebug() {
 
 // Do 执行聚合
 func (b *AggregationBuilder) Do(ctx context.Context) (*AggregationResponse, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
 	path := fmt.Sprintf("/%s/_search", b.index)
 	body := b.Build()
 
-	// 如果启用调试模式，打印请求信息
+	// 如果启用调试模式，由客户端打印请求和响应信息
 	if b.debug {
-		b.printDebug("POST", path, body)
+		ctx = client.WithDebug(ctx)
 		defer b.resetDebug()
 	}
 
@@ -441,13 +476,8 @@ func (b *AggregationBuilder) Do(ctx context.Context) (*AggregationResponse, erro
 		return nil, err
 	}
 
-	// 如果启用调试模式，打印响应信息
-	if b.debug {
-		b.printResponse(respBody)
-	}
-
 	var resp AggregationResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := b.client.Codec().Unmarshal(respBody, &resp); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
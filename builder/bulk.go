@@ -3,7 +3,6 @@ package builder
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 
@@ -315,8 +314,7 @@ func (b *BulkBuilder) SetFromStruct(data interface{}) *BulkBuilder {
 	if b.currentOp == nil {
 		panic("SetFromStruct() must be called after AddDoc/CreateDoc/UpdateDoc")
 	}
-	jsonData, _ := json.Marshal(data)
-	json.Unmarshal(jsonData, &b.currentOp.doc)
+	structToMap(b.client.Codec(), data, &b.currentOp.doc)
 	return b
 }
 
@@ -325,7 +323,7 @@ func (b *BulkBuilder) SetObject(key string, builder func(*NestedObject)) *BulkBu
 	if b.currentOp == nil {
 		panic("SetObject() must be called after AddDoc/CreateDoc/UpdateDoc")
 	}
-	nested := &NestedObject{data: make(map[string]interface{})}
+	nested := newNestedObject(b.client.Codec())
 	builder(nested)
 	b.currentOp.doc[key] = nested.data
 	return b
@@ -347,7 +345,7 @@ func (b *BulkBuilder) SetObjectArray(key string, builders ...func(*NestedObject)
 	}
 	arr := make([]map[string]interface{}, len(builders))
 	for i, builder := range builders {
-		nested := &NestedObject{data: make(map[string]interface{})}
+		nested := newNestedObject(b.client.Codec())
 		builder(nested)
 		arr[i] = nested.data
 	}
@@ -358,16 +356,14 @@ func (b *BulkBuilder) SetObjectArray(key string, builders ...func(*NestedObject)
 // AddFromStruct 从结构体添加索引操作
 func (b *BulkBuilder) AddFromStruct(index, id string, data interface{}) *BulkBuilder {
 	doc := make(map[string]interface{})
-	jsonData, _ := json.Marshal(data)
-	json.Unmarshal(jsonData, &doc)
+	structToMap(b.client.Codec(), data, &doc)
 	return b.Add(index, id, doc)
 }
 
 // UpdateFromStruct 从结构体添加更新操作
 func (b *BulkBuilder) UpdateFromStruct(index, id string, data interface{}) *BulkBuilder {
 	doc := make(map[string]interface{})
-	jsonData, _ := json.Marshal(data)
-	json.Unmarshal(jsonData, &doc)
+	structToMap(b.client.Codec(), data, &doc)
 	return b.Update(index, id, doc)
 }
 
@@ -426,26 +422,49 @@ func (r *BulkResponse) SuccessCount() int {
 func (b *BulkBuilder) Build() []byte {
 	b.commitCurrent() // 提交链式构建的操作（如果有）
 
-	var buf bytes.Buffer
+	body, _ := b.encode()
+	return body
+}
+
+// encode 将批量操作编码为 NDJSON
+// 先逐行编码，再按总长度一次性分配请求体，避免缓冲区扩容时反复复制
+func (b *BulkBuilder) encode() ([]byte, error) {
+	codec := b.client.Codec()
+	lines := make([][]byte, 0, len(b.operations)*2)
+	size := 0
+	addLine := func(v interface{}) error {
+		line, err := codec.Marshal(v)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, line)
+		size += len(line) + 1
+		return nil
+	}
 
 	for _, op := range b.operations {
-		// 写入操作行
+		// 操作行
 		action := map[string]interface{}{
 			op.action: op.meta,
 		}
-		actionLine, _ := json.Marshal(action)
-		buf.Write(actionLine)
-		buf.WriteByte('\n')
+		if err := addLine(action); err != nil {
+			return nil, err
+		}
 
-		// 写入文档行（delete 操作不需要）
+		// 文档行（delete 操作不需要）
 		if op.action != "delete" && op.doc != nil {
-			docLine, _ := json.Marshal(op.doc)
-			buf.Write(docLine)
-			buf.WriteByte('\n')
+			if err := addLine(op.doc); err != nil {
+				return nil, err
+			}
 		}
 	}
 
-	return buf.Bytes()
+	body := make([]byte, 0, size)
+	for _, line := range lines {
+		body = append(body, line...)
+		body = append(body, '\n')
+	}
+	return body, nil
 }
 
 // Debug 启用调试模式（链式调用）
@@ -454,23 +473,6 @@ func (b *BulkBuilder) Debug() *BulkBuilder {
 	return b
 }
 
-// printDebug 打印请求调试信息
-func (b *BulkBuilder) printDebug(method, path string, body []byte) {
-	fmt.Printf("\n[ES Debug] %s %s\n", method, path)
-	if body != nil {
-		// Bulk API 使用 NDJSON 格式，直接打印
-		fmt.Printf("Request Body (NDJSON):\n%s\n", string(body))
-	}
-}
-
-// printResponse 打印响应调试信息
-func (b *BulkBuilder) printResponse(respBody []byte) {
-	var pretty interface{}
-	json.Unmarshal(respBody, &pretty)
-	data, _ := json.MarshalIndent(pretty, "", "  ")
-	fmt.Printf("Response:\n%s\n\n", string(data))
-}
-
 // resetDebug 执行后重置debug标志（让每次调用可以独立控制）
 func (b *BulkBuilder) resetDebug() {
 	b.debug = false
@@ -485,17 +487,22 @@ func (b *BulkBuilder) Do(ctx context.Context) (*BulkResponse, error) {
 	}
 
 	path := "/_bulk"
-	body := b.Build()
 
-	// 如果启用调试模式，打印请求信息
+	// 如果启用调试模式，由客户端打印请求和响应信息
 	if b.debug {
-		b.printDebug("POST", path, body)
+		ctx = client.WithDebug(ctx)
 		defer b.resetDebug()
 	}
 
-	// 创建请求
-	url := b.client.GetAddress() + path
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	// 请求体只序列化一次，客户端每次尝试通过 GetBody 重新读取同一份数据，不复制；
+	// 连接失败或节点不可用时可以重放，按重试策略重试或切换节点
+	body, err := b.encode()
+	if err != nil {
+		return nil, fmt.Errorf("序列化请求体失败: %w", err)
+	}
+
+	// 创建请求，节点由客户端在执行时选择
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, path, bytes.NewReader(body))
 	if err != nil {
 		return nil, fmt.Errorf("创建请求失败: %w", err)
 	}
@@ -504,21 +511,22 @@ func (b *BulkBuilder) Do(ctx context.Context) (*BulkResponse, error) {
 	req.Header.Set("Content-Type", "application/x-ndjson")
 
 	// 执行请求
-	respBody, err := b.client.DoRequest(ctx, req)
+	stream, err := b.client.DoStream(ctx, req)
 	if err != nil {
 		return nil, err
 	}
-
-	// 如果启用调试模式，打印响应信息
-	if b.debug {
-		b.printResponse(respBody)
-	}
+	defer stream.Close()
 
 	var resp BulkResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := b.client.Codec().NewDecoder(stream).Decode(&resp); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
+	// 记录批量操作单项的成功/失败数量
+	if m := b.client.Metrics(); m != nil {
+		m.ObserveBulkItems(resp.SuccessCount(), len(resp.FailedItems()))
+	}
+
 	return &resp, nil
 }
 
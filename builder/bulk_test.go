@@ -3,9 +3,21 @@ package builder
 import (
 	"context"
 	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/Kirby980/go-es/client"
+	"github.com/Kirby980/go-es/config"
+	"github.com/Kirby980/go-es/metrics"
 )
 
 // TestBulkBuilder_IndexOperations 测试批量索引操作
@@ -848,3 +860,167 @@ func TestBulkBuilder_Flush(t *testing.T) {
 
 	t.Logf("✓ 自动分批提交测试通过")
 }
+
+// TestBulkBuilder_RetryOnUnavailable 测试节点不可用时批量请求重放请求体并重试（与是否调试无关）
+func TestBulkBuilder_RetryOnUnavailable(t *testing.T) {
+	for _, debug := range []bool{false, true} {
+		t.Run(fmt.Sprintf("debug=%v", debug), func(t *testing.T) {
+			var (
+				mu     sync.Mutex
+				bodies []string
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				data, _ := io.ReadAll(r.Body)
+				mu.Lock()
+				bodies = append(bodies, string(data))
+				attempt := len(bodies)
+				mu.Unlock()
+
+				if attempt == 1 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					w.Write([]byte(`{"error":{"type":"unavailable","reason":"node unavailable"},"status":503}`))
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"took":1,"errors":false,"items":[{"index":{"_index":"test","_id":"1","status":201,"result":"created"}}]}`))
+			}))
+			defer server.Close()
+
+			esClient, err := client.New(
+				config.WithAddresses(server.URL),
+				config.WithRetry(2, time.Millisecond),
+				config.WithLogger(slog.New(slog.DiscardHandler)),
+			)
+			if err != nil {
+				t.Fatalf("创建客户端失败: %v", err)
+			}
+			defer esClient.Close()
+
+			bulk := NewBulkBuilder(esClient).Index("test").Add("", "1", map[string]interface{}{"title": "文档1"})
+			if debug {
+				bulk.Debug()
+			}
+			resp, err := bulk.Do(context.Background())
+			if err != nil {
+				t.Fatalf("批量操作应该在重试后成功: %v", err)
+			}
+			if resp.SuccessCount() != 1 {
+				t.Errorf("期望成功 1 个, 实际=%d", resp.SuccessCount())
+			}
+			if len(bodies) != 2 {
+				t.Fatalf("应该发送 2 次请求，实际 %d", len(bodies))
+			}
+			if bodies[0] == "" || bodies[0] != bodies[1] {
+				t.Errorf("重试应该发送相同的请求体:\n%s\n%s", bodies[0], bodies[1])
+			}
+
+			t.Logf("✓ 批量操作重试成功")
+		})
+	}
+}
+
+// TestBulkBuilder_MetricsItems 测试批量操作单项的成功/失败数量统计
+func TestBulkBuilder_MetricsItems(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"took":1,"errors":true,"items":[
+			{"index":{"_index":"test","_id":"1","status":201,"result":"created"}},
+			{"index":{"_index":"test","_id":"2","status":200,"result":"updated"}},
+			{"index":{"_index":"test","_id":"3","status":400,"error":{"type":"mapper_parsing_exception","reason":"failed to parse"}}}
+		]}`))
+	}))
+	defer server.Close()
+
+	m := metrics.NewInMemory()
+	esClient, err := client.New(config.WithAddresses(server.URL), config.WithMetricsCollector(m))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer esClient.Close()
+
+	_, err = NewBulkBuilder(esClient).Index("test").
+		Add("", "1", map[string]interface{}{"n": 1}).
+		Add("", "2", map[string]interface{}{"n": 2}).
+		Add("", "3", map[string]interface{}{"n": "x"}).
+		Do(context.Background())
+	if err != nil {
+		t.Fatalf("批量操作失败: %v", err)
+	}
+
+	s := m.Snapshot()
+	if s.BulkItemsSucceeded != 2 || s.BulkItemsFailed != 1 {
+		t.Fatalf("应该统计成功 2 个、失败 1 个，实际 %d、%d", s.BulkItemsSucceeded, s.BulkItemsFailed)
+	}
+
+	t.Logf("✓ 统计批量操作单项结果")
+}
+
+// TestBulkBuilder_RequestBodyNotCopied 测试批量请求体只序列化一次，重试时不复制
+func TestBulkBuilder_RequestBodyNotCopied(t *testing.T) {
+	var (
+		count    int32
+		received []int64
+		mu       sync.Mutex
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := io.Copy(io.Discard, r.Body)
+		mu.Lock()
+		received = append(received, n)
+		mu.Unlock()
+		if atomic.AddInt32(&count, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"took":1,"errors":false,"items":[]}`))
+	}))
+	defer server.Close()
+
+	esClient, err := client.New(config.WithAddresses(server.URL), config.WithRetry(1, time.Millisecond))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer esClient.Close()
+
+	text := strings.Repeat("x", 1024)
+	bulk := NewBulkBuilder(esClient).Index("test")
+	for i := 0; i < 8*1024; i++ {
+		bulk.Add("", strconv.Itoa(i), map[string]interface{}{"text": text})
+	}
+	size := len(bulk.Build())
+
+	// 序列化本身的分配作为基准
+	encoded := allocatedBytes(func() {
+		if _, err := bulk.encode(); err != nil {
+			t.Fatalf("序列化失败: %v", err)
+		}
+	})
+
+	allocated := allocatedBytes(func() {
+		_, err = bulk.Do(context.Background())
+	})
+	if err != nil {
+		t.Fatalf("批量操作应该在重试后成功: %v", err)
+	}
+
+	if len(received) != 2 || received[0] != int64(size) || received[1] != int64(size) {
+		t.Fatalf("两次尝试都应该发送 %d 字节的完整请求体，实际 %v", size, received)
+	}
+	// 除序列化外，两次尝试发送请求只需要少量分配；请求体每被复制一次至少多分配 1 倍
+	if extra := int64(allocated) - int64(encoded); extra > int64(size)/2 {
+		t.Fatalf("请求体不应该被复制，%d 字节的请求体在序列化之外分配了 %d 字节", size, extra)
+	}
+
+	t.Logf("✓ %d 字节的请求体序列化分配 %d 字节，执行共分配 %d 字节", size, encoded, allocated)
+}
+
+// allocatedBytes 返回执行 f 期间分配的内存字节数
+func allocatedBytes(f func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	f()
+	runtime.ReadMemStats(&after)
+	return after.TotalAlloc - before.TotalAlloc
+}
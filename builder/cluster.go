@@ -2,7 +2,6 @@ package builder
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 
@@ -29,23 +28,6 @@ func (b *ClusterBuilder) Debug() *ClusterBuilder {
 	return b
 }
 
-// printDebug 打印请求调试信息
-func (b *ClusterBuilder) printDebug(method, path string, body interface{}) {
-	fmt.Printf("\n[ES Debug] %s %s\n", method, path)
-	if body != nil {
-		data, _ := json.MarshalIndent(body, "", "  ")
-		fmt.Printf("Request Body:\n%s\n", string(data))
-	}
-}
-
-// printResponse 打印响应调试信息
-func (b *ClusterBuilder) printResponse(respBody []byte) {
-	var pretty interface{}
-	json.Unmarshal(respBody, &pretty)
-	data, _ := json.MarshalIndent(pretty, "", "  ")
-	fmt.Printf("Response:\n%s\n\n", string(data))
-}
-
 // resetDebug 执行后重置debug标志（让每次调用可以独立控制）
 func (b *ClusterBuilder) resetDebug() {
 	b.debug = false
@@ -76,9 +58,9 @@ type ClusterHealthResponse struct {
 func (b *ClusterBuilder) Health(ctx context.Context) (*ClusterHealthResponse, error) {
 	path := "/_cluster/health"
 
-	// 如果启用调试模式，打印请求信息
+	// 如果启用调试模式，由客户端打印请求和响应信息
 	if b.debug {
-		b.printDebug("GET", path, nil)
+		ctx = client.WithDebug(ctx)
 		defer b.resetDebug()
 	}
 
@@ -87,13 +69,8 @@ func (b *ClusterBuilder) Health(ctx context.Context) (*ClusterHealthResponse, er
 		return nil, err
 	}
 
-	// 如果启用调试模式，打印响应信息
-	if b.debug {
-		b.printResponse(respBody)
-	}
-
 	var resp ClusterHealthResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := b.client.Codec().Unmarshal(respBody, &resp); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
@@ -109,7 +86,7 @@ func (b *ClusterBuilder) IndexHealth(ctx context.Context, index string) (*Cluste
 	}
 
 	var resp ClusterHealthResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := b.client.Codec().Unmarshal(respBody, &resp); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
@@ -141,7 +118,7 @@ func (b *ClusterBuilder) State(ctx context.Context) (*ClusterStateResponse, erro
 	}
 
 	var resp ClusterStateResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := b.client.Codec().Unmarshal(respBody, &resp); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
@@ -173,9 +150,9 @@ type ClusterStatsResponse struct {
 func (b *ClusterBuilder) Stats(ctx context.Context) (*ClusterStatsResponse, error) {
 	path := "/_cluster/stats"
 
-	// 如果启用调试模式，打印请求信息
+	// 如果启用调试模式，由客户端打印请求和响应信息
 	if b.debug {
-		b.printDebug("GET", path, nil)
+		ctx = client.WithDebug(ctx)
 		defer b.resetDebug()
 	}
 
@@ -184,13 +161,8 @@ func (b *ClusterBuilder) Stats(ctx context.Context) (*ClusterStatsResponse, erro
 		return nil, err
 	}
 
-	// 如果启用调试模式，打印响应信息
-	if b.debug {
-		b.printResponse(respBody)
-	}
-
 	var resp ClusterStatsResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := b.client.Codec().Unmarshal(respBody, &resp); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
@@ -214,7 +186,7 @@ func (b *ClusterBuilder) NodesInfo(ctx context.Context) (*NodesInfoResponse, err
 	}
 
 	var resp NodesInfoResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := b.client.Codec().Unmarshal(respBody, &resp); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
@@ -238,7 +210,7 @@ func (b *ClusterBuilder) NodesStats(ctx context.Context) (*NodesStatsResponse, e
 	}
 
 	var resp NodesStatsResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := b.client.Codec().Unmarshal(respBody, &resp); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
@@ -262,13 +234,24 @@ func (b *ClusterBuilder) Tasks(ctx context.Context) (*TasksResponse, error) {
 	}
 
 	var resp TasksResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := b.client.Codec().Unmarshal(respBody, &resp); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
 	return &resp, nil
 }
 
+// Task 获取指定任务的状态（如 ReindexBuilder.Start 返回的任务）
+func (b *ClusterBuilder) Task(ctx context.Context, taskID string) (*TaskStatus, error) {
+	// 如果启用调试模式，由客户端打印请求和响应信息
+	if b.debug {
+		ctx = client.WithDebug(ctx)
+		defer b.resetDebug()
+	}
+
+	return NewTaskHandle(b.client, taskID).Status(ctx)
+}
+
 // ========== 集群设置 ==========
 
 // ClusterSettingsResponse 集群设置响应
@@ -286,7 +269,7 @@ func (b *ClusterBuilder) GetSettings(ctx context.Context) (*ClusterSettingsRespo
 	}
 
 	var resp ClusterSettingsResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := b.client.Codec().Unmarshal(respBody, &resp); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
@@ -304,22 +287,17 @@ func (b *ClusterBuilder) UpdateSettings(ctx context.Context, persistent, transie
 		body["transient"] = transient
 	}
 
-	// 如果启用调试模式，打印请求信息
+	// 如果启用调试模式，由客户端打印请求和响应信息
 	if b.debug {
-		b.printDebug("PUT", path, body)
+		ctx = client.WithDebug(ctx)
 		defer b.resetDebug()
 	}
 
-	respBody, err := b.client.Do(ctx, http.MethodPut, path, body)
+	_, err := b.client.Do(ctx, http.MethodPut, path, body)
 	if err != nil {
 		return err
 	}
 
-	// 如果启用调试模式，打印响应信息
-	if b.debug {
-		b.printResponse(respBody)
-	}
-
 	return nil
 }
 
@@ -352,7 +330,7 @@ func (b *ClusterBuilder) AllocationExplain(ctx context.Context, index string, sh
 	}
 
 	var resp AllocationExplainResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := b.client.Codec().Unmarshal(respBody, &resp); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
@@ -373,7 +351,7 @@ func (b *ClusterBuilder) RemoteClusters(ctx context.Context) (RemoteClustersResp
 	}
 
 	var resp RemoteClustersResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := b.client.Codec().Unmarshal(respBody, &resp); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
@@ -4,20 +4,28 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
 	"net/http"
+	"net/url"
+	"strconv"
 
 	"github.com/Kirby980/go-es/client"
+	"github.com/Kirby980/go-es/query"
 )
 
 // DeleteByQueryBuilder 按查询删除构建器
 type DeleteByQueryBuilder struct {
-	client  *client.Client
-	index   string
-	filters []map[string]interface{}
-	must    []map[string]interface{}
-	should  []map[string]interface{}
-	mustNot []map[string]interface{}
-	debug   bool
+	client    *client.Client
+	index     string
+	filters   []map[string]interface{}
+	must      []map[string]interface{}
+	should    []map[string]interface{}
+	mustNot   []map[string]interface{}
+	rootQuery query.Query // Query 设置的查询
+	params    url.Values
+	extra     map[string]interface{} // Extra 设置的顶层键
+	err       error                  // RawQuery、FromJSON 的解析错误
+	debug     bool
 }
 
 // NewDeleteByQueryBuilder 创建按查询删除构建器
@@ -29,9 +37,60 @@ func NewDeleteByQueryBuilder(c *client.Client, index string) *DeleteByQueryBuild
 		must:    make([]map[string]interface{}, 0),
 		should:  make([]map[string]interface{}, 0),
 		mustNot: make([]map[string]interface{}, 0),
+		params:  url.Values{},
 	}
 }
 
+// Query 设置查询（query 包构建的查询节点）
+// 与 Match、Term 等链式方法添加的条件同时使用时，以 bool.must 组合
+func (b *DeleteByQueryBuilder) Query(q query.Query) *DeleteByQueryBuilder {
+	b.rootQuery = q
+	return b
+}
+
+// RawQuery 使用原始 JSON 设置查询
+// 与 Match、Term 等链式方法添加的条件同时使用时，以 bool.must 组合
+func (b *DeleteByQueryBuilder) RawQuery(raw json.RawMessage) *DeleteByQueryBuilder {
+	q, err := rawQuery(raw)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	b.rootQuery = query.Raw(q)
+	return b
+}
+
+// Extra 设置请求体的顶层键，如 max_docs、conflicts、slice
+// 与构建器生成的同名键（query）冲突时以构建器为准
+func (b *DeleteByQueryBuilder) Extra(key string, value interface{}) *DeleteByQueryBuilder {
+	b.extra = setExtra(b.extra, key, value)
+	return b
+}
+
+// FromJSON 加载保存的请求体
+// query 写入构建器，其余键等同于 Extra
+func (b *DeleteByQueryBuilder) FromJSON(data []byte) *DeleteByQueryBuilder {
+	body, err := decodeJSONObject(data)
+	if err != nil {
+		b.err = err
+		return b
+	}
+
+	for key, value := range body {
+		switch key {
+		case "query":
+			b.rootQuery, err = jsonQuery(value)
+		default:
+			b.extra = setExtra(b.extra, key, value)
+		}
+		if err != nil {
+			b.err = fmt.Errorf("加载请求体失败: %w", err)
+			return b
+		}
+	}
+	return b
+}
+
 // Match 添加 match 查询条件
 func (b *DeleteByQueryBuilder) Match(field string, value interface{}) *DeleteByQueryBuilder {
 	b.must = append(b.must, map[string]interface{}{
@@ -89,27 +148,54 @@ func (b *DeleteByQueryBuilder) Exists(field string) *DeleteByQueryBuilder {
 	return b
 }
 
-// Debug 启用调试模式
-func (b *DeleteByQueryBuilder) Debug() *DeleteByQueryBuilder {
-	b.debug = true
+// ========== 执行控制 ==========
+
+// ConflictsProceed 遇到版本冲突时继续执行（默认遇到冲突中止）
+func (b *DeleteByQueryBuilder) ConflictsProceed() *DeleteByQueryBuilder {
+	b.params.Set("conflicts", "proceed")
 	return b
 }
 
-// printDebug 打印请求调试信息
-func (b *DeleteByQueryBuilder) printDebug(method, path string, body interface{}) {
-	fmt.Printf("\n[ES Debug] %s %s\n", method, path)
-	if body != nil {
-		data, _ := json.MarshalIndent(body, "", "  ")
-		fmt.Printf("Request Body:\n%s\n", string(data))
-	}
+// Slices 设置切片数，并行执行删除
+func (b *DeleteByQueryBuilder) Slices(slices int) *DeleteByQueryBuilder {
+	b.params.Set("slices", strconv.Itoa(slices))
+	return b
 }
 
-// printResponse 打印响应调试信息
-func (b *DeleteByQueryBuilder) printResponse(respBody []byte) {
-	var pretty interface{}
-	json.Unmarshal(respBody, &pretty)
-	data, _ := json.MarshalIndent(pretty, "", "  ")
-	fmt.Printf("Response:\n%s\n\n", string(data))
+// SlicesAuto 由 ES 根据分片数自动选择切片数
+func (b *DeleteByQueryBuilder) SlicesAuto() *DeleteByQueryBuilder {
+	b.params.Set("slices", "auto")
+	return b
+}
+
+// ScrollSize 设置每批处理的文档数（默认 1000）
+func (b *DeleteByQueryBuilder) ScrollSize(size int) *DeleteByQueryBuilder {
+	b.params.Set("scroll_size", strconv.Itoa(size))
+	return b
+}
+
+// MaxDocs 最多处理的文档数
+func (b *DeleteByQueryBuilder) MaxDocs(maxDocs int) *DeleteByQueryBuilder {
+	b.params.Set("max_docs", strconv.Itoa(maxDocs))
+	return b
+}
+
+// RequestsPerSecond 限制每秒处理的文档数，-1 表示不限制
+func (b *DeleteByQueryBuilder) RequestsPerSecond(rps float64) *DeleteByQueryBuilder {
+	b.params.Set("requests_per_second", strconv.FormatFloat(rps, 'f', -1, 64))
+	return b
+}
+
+// Refresh 完成后刷新索引
+func (b *DeleteByQueryBuilder) Refresh() *DeleteByQueryBuilder {
+	b.params.Set("refresh", "true")
+	return b
+}
+
+// Debug 启用调试模式
+func (b *DeleteByQueryBuilder) Debug() *DeleteByQueryBuilder {
+	b.debug = true
+	return b
 }
 
 // resetDebug 执行后重置debug标志（让每次调用可以独立控制）
@@ -122,37 +208,22 @@ func (b *DeleteByQueryBuilder) Build() map[string]interface{} {
 	body := make(map[string]interface{})
 
 	// 构建查询条件
-	if len(b.must) > 0 || len(b.filters) > 0 || len(b.should) > 0 || len(b.mustNot) > 0 {
-		boolQuery := make(map[string]interface{})
-		if len(b.must) > 0 {
-			boolQuery["must"] = b.must
-		}
-		if len(b.filters) > 0 {
-			boolQuery["filter"] = b.filters
-		}
-		if len(b.should) > 0 {
-			boolQuery["should"] = b.should
-		}
-		if len(b.mustNot) > 0 {
-			boolQuery["must_not"] = b.mustNot
-		}
-		body["query"] = map[string]interface{}{
-			"bool": boolQuery,
-		}
+	if q := buildQuery(b.rootQuery, b.must, b.filters, b.should, b.mustNot, nil); q != nil {
+		body["query"] = q
 	}
 
-	return body
+	return mergeExtra(body, b.extra)
 }
 
 // DeleteByQueryResponse 删除响应
 type DeleteByQueryResponse struct {
-	Took             int    `json:"took"`
-	TimedOut         bool   `json:"timed_out"`
-	Total            int    `json:"total"`
-	Deleted          int    `json:"deleted"`
-	Batches          int    `json:"batches"`
-	VersionConflicts int    `json:"version_conflicts"`
-	Noops            int    `json:"noops"`
+	Took             int  `json:"took"`
+	TimedOut         bool `json:"timed_out"`
+	Total            int  `json:"total"`
+	Deleted          int  `json:"deleted"`
+	Batches          int  `json:"batches"`
+	VersionConflicts int  `json:"version_conflicts"`
+	Noops            int  `json:"noops"`
 	Retries          struct {
 		Bulk   int `json:"bulk"`
 		Search int `json:"search"`
@@ -163,19 +234,23 @@ type DeleteByQueryResponse struct {
 	Failures             []map[string]interface{} `json:"failures"`
 }
 
-// Do 执行删除
+// Do 执行删除，数据量较大时建议使用 Start 避免请求超时
 func (b *DeleteByQueryBuilder) Do(ctx context.Context) (*DeleteByQueryResponse, error) {
-	path := fmt.Sprintf("/%s/_delete_by_query", b.index)
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	path := requestPath(fmt.Sprintf("/%s/_delete_by_query", b.index), b.params)
 	body := b.Build()
 
-	// 检查是否有查询条件
-	if len(body) == 0 {
+	// 检查是否有查询条件（Extra 设置的其他键不算）
+	if body["query"] == nil {
 		return nil, fmt.Errorf("必须设置查询条件，避免误删除所有数据")
 	}
 
-	// 如果启用调试模式，打印请求信息
+	// 如果启用调试模式，由客户端打印请求和响应信息
 	if b.debug {
-		b.printDebug("POST", path, body)
+		ctx = client.WithDebug(ctx)
 		defer b.resetDebug()
 	}
 
@@ -184,15 +259,38 @@ func (b *DeleteByQueryBuilder) Do(ctx context.Context) (*DeleteByQueryResponse,
 		return nil, err
 	}
 
-	// 如果启用调试模式，打印响应信息
-	if b.debug {
-		b.printResponse(respBody)
-	}
-
 	var resp DeleteByQueryResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := b.client.Codec().Unmarshal(respBody, &resp); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
 	return &resp, nil
 }
+
+// Start 异步执行删除，返回任务句柄
+// 通过 TaskHandle.Wait 等待完成，再用 TaskStatus.DecodeResponse 解析为 DeleteByQueryResponse
+func (b *DeleteByQueryBuilder) Start(ctx context.Context) (*TaskHandle, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	body := b.Build()
+
+	// 检查是否有查询条件（Extra 设置的其他键不算）
+	if body["query"] == nil {
+		return nil, fmt.Errorf("必须设置查询条件，避免误删除所有数据")
+	}
+
+	// 复制查询参数，避免 Start 修改构建器状态
+	params := maps.Clone(b.params)
+	params.Set("wait_for_completion", "false")
+	path := requestPath(fmt.Sprintf("/%s/_delete_by_query", b.index), params)
+
+	// 如果启用调试模式，由客户端打印请求和响应信息
+	if b.debug {
+		ctx = client.WithDebug(ctx)
+		defer b.resetDebug()
+	}
+
+	return startTask(ctx, b.client, http.MethodPost, path, body)
+}
@@ -2,7 +2,6 @@ package builder
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 
@@ -17,6 +16,7 @@ type DocumentBuilder struct {
 	doc     map[string]interface{}
 	script  map[string]interface{}
 	refresh string // refresh 参数: true, false, wait_for
+	noRetry bool   // 禁用重试（非幂等操作）
 	debug   bool   // 调试模式标志
 }
 
@@ -51,14 +51,13 @@ func (b *DocumentBuilder) SetMap(data map[string]interface{}) *DocumentBuilder {
 
 // SetStruct 从结构体设置
 func (b *DocumentBuilder) SetStruct(data interface{}) *DocumentBuilder {
-	jsonData, _ := json.Marshal(data)
-	json.Unmarshal(jsonData, &b.doc)
+	structToMap(b.client.Codec(), data, &b.doc)
 	return b
 }
 
 // SetObject 设置嵌套对象
 func (b *DocumentBuilder) SetObject(key string, builder func(*NestedObject)) *DocumentBuilder {
-	nested := &NestedObject{data: make(map[string]interface{})}
+	nested := newNestedObject(b.client.Codec())
 	builder(nested)
 	b.doc[key] = nested.data
 	return b
@@ -74,7 +73,7 @@ func (b *DocumentBuilder) SetArray(key string, values ...interface{}) *DocumentB
 func (b *DocumentBuilder) SetObjectArray(key string, builders ...func(*NestedObject)) *DocumentBuilder {
 	arr := make([]map[string]interface{}, len(builders))
 	for i, builder := range builders {
-		nested := &NestedObject{data: make(map[string]interface{})}
+		nested := newNestedObject(b.client.Codec())
 		builder(nested)
 		arr[i] = nested.data
 	}
@@ -91,6 +90,22 @@ func (b *DocumentBuilder) Refresh(refresh string) *DocumentBuilder {
 	return b
 }
 
+// NoRetry 禁用写操作的自动重试
+// 适用于非幂等写入（如脚本自增），避免请求已生效但响应丢失时被重复执行
+func (b *DocumentBuilder) NoRetry() *DocumentBuilder {
+	b.noRetry = true
+	return b
+}
+
+// writeContext 返回写操作使用的 context
+// 未指定 ID 的索引请求（POST 自动生成 ID）重试会产生重复文档，因此始终禁用重试
+func (b *DocumentBuilder) writeContext(ctx context.Context) context.Context {
+	if b.noRetry || b.id == "" {
+		return client.WithoutRetry(ctx)
+	}
+	return ctx
+}
+
 // buildPath 构建带查询参数的路径
 func (b *DocumentBuilder) buildPath(basePath string) string {
 	if b.refresh != "" {
@@ -105,23 +120,6 @@ func (b *DocumentBuilder) Debug() *DocumentBuilder {
 	return b
 }
 
-// printDebug 打印请求调试信息
-func (b *DocumentBuilder) printDebug(method, path string, body interface{}) {
-	fmt.Printf("\n[ES Debug] %s %s\n", method, path)
-	if body != nil {
-		data, _ := json.MarshalIndent(body, "", "  ")
-		fmt.Printf("Request Body:\n%s\n", string(data))
-	}
-}
-
-// printResponse 打印响应调试信息
-func (b *DocumentBuilder) printResponse(respBody []byte) {
-	var pretty interface{}
-	json.Unmarshal(respBody, &pretty)
-	data, _ := json.MarshalIndent(pretty, "", "  ")
-	fmt.Printf("Response:\n%s\n\n", string(data))
-}
-
 // resetDebug 执行后重置debug标志（让每次调用可以独立控制）
 func (b *DocumentBuilder) resetDebug() {
 	b.debug = false
@@ -177,24 +175,19 @@ func (b *DocumentBuilder) Do(ctx context.Context) (*DocumentResponse, error) {
 	// 添加查询参数
 	path = b.buildPath(path)
 
-	// 如果启用调试模式，打印请求信息
+	// 如果启用调试模式，由客户端打印请求和响应信息
 	if b.debug {
-		b.printDebug(method, path, b.doc)
+		ctx = client.WithDebug(ctx)
 		defer b.resetDebug()
 	}
 
-	respBody, err := b.client.Do(ctx, method, path, b.doc)
+	respBody, err := b.client.Do(b.writeContext(ctx), method, path, b.doc)
 	if err != nil {
 		return nil, err
 	}
 
-	// 如果启用调试模式，打印响应信息
-	if b.debug {
-		b.printResponse(respBody)
-	}
-
 	var resp DocumentResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := b.client.Codec().Unmarshal(respBody, &resp); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
@@ -210,24 +203,19 @@ func (b *DocumentBuilder) Create(ctx context.Context) (*DocumentResponse, error)
 	path := fmt.Sprintf("/%s/_create/%s", b.index, b.id)
 	path = b.buildPath(path)
 
-	// 如果启用调试模式，打印请求信息
+	// 如果启用调试模式，由客户端打印请求和响应信息
 	if b.debug {
-		b.printDebug("PUT", path, b.doc)
+		ctx = client.WithDebug(ctx)
 		defer b.resetDebug()
 	}
 
-	respBody, err := b.client.Do(ctx, http.MethodPut, path, b.doc)
+	respBody, err := b.client.Do(b.writeContext(ctx), http.MethodPut, path, b.doc)
 	if err != nil {
 		return nil, err
 	}
 
-	// 如果启用调试模式，打印响应信息
-	if b.debug {
-		b.printResponse(respBody)
-	}
-
 	var resp DocumentResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := b.client.Codec().Unmarshal(respBody, &resp); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
@@ -250,24 +238,19 @@ func (b *DocumentBuilder) Update(ctx context.Context) (*DocumentResponse, error)
 		updateBody["doc"] = b.doc
 	}
 
-	// 如果启用调试模式，打印请求信息
+	// 如果启用调试模式，由客户端打印请求和响应信息
 	if b.debug {
-		b.printDebug("POST", path, updateBody)
+		ctx = client.WithDebug(ctx)
 		defer b.resetDebug()
 	}
 
-	respBody, err := b.client.Do(ctx, http.MethodPost, path, updateBody)
+	respBody, err := b.client.Do(b.writeContext(ctx), http.MethodPost, path, updateBody)
 	if err != nil {
 		return nil, err
 	}
 
-	// 如果启用调试模式，打印响应信息
-	if b.debug {
-		b.printResponse(respBody)
-	}
-
 	var resp DocumentResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := b.client.Codec().Unmarshal(respBody, &resp); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
@@ -288,24 +271,19 @@ func (b *DocumentBuilder) Upsert(ctx context.Context) (*DocumentResponse, error)
 		"doc_as_upsert": true,
 	}
 
-	// 如果启用调试模式，打印请求信息
+	// 如果启用调试模式，由客户端打印请求和响应信息
 	if b.debug {
-		b.printDebug("POST", path, updateBody)
+		ctx = client.WithDebug(ctx)
 		defer b.resetDebug()
 	}
 
-	respBody, err := b.client.Do(ctx, http.MethodPost, path, updateBody)
+	respBody, err := b.client.Do(b.writeContext(ctx), http.MethodPost, path, updateBody)
 	if err != nil {
 		return nil, err
 	}
 
-	// 如果启用调试模式，打印响应信息
-	if b.debug {
-		b.printResponse(respBody)
-	}
-
 	var resp DocumentResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := b.client.Codec().Unmarshal(respBody, &resp); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
@@ -320,9 +298,9 @@ func (b *DocumentBuilder) Get(ctx context.Context) (*GetResponse, error) {
 
 	path := fmt.Sprintf("/%s/_doc/%s", b.index, b.id)
 
-	// 如果启用调试模式，打印请求信息
+	// 如果启用调试模式，由客户端打印请求和响应信息
 	if b.debug {
-		b.printDebug("GET", path, nil)
+		ctx = client.WithDebug(ctx)
 		defer b.resetDebug()
 	}
 
@@ -331,13 +309,8 @@ func (b *DocumentBuilder) Get(ctx context.Context) (*GetResponse, error) {
 		return nil, err
 	}
 
-	// 如果启用调试模式，打印响应信息
-	if b.debug {
-		b.printResponse(respBody)
-	}
-
 	var resp GetResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := b.client.Codec().Unmarshal(respBody, &resp); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
@@ -353,9 +326,9 @@ func (b *DocumentBuilder) Delete(ctx context.Context) (*DocumentResponse, error)
 	path := fmt.Sprintf("/%s/_doc/%s", b.index, b.id)
 	path = b.buildPath(path)
 
-	// 如果启用调试模式，打印请求信息
+	// 如果启用调试模式，由客户端打印请求和响应信息
 	if b.debug {
-		b.printDebug("DELETE", path, nil)
+		ctx = client.WithDebug(ctx)
 		defer b.resetDebug()
 	}
 
@@ -364,13 +337,8 @@ func (b *DocumentBuilder) Delete(ctx context.Context) (*DocumentResponse, error)
 		return nil, err
 	}
 
-	// 如果启用调试模式，打印响应信息
-	if b.debug {
-		b.printResponse(respBody)
-	}
-
 	var resp DocumentResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := b.client.Codec().Unmarshal(respBody, &resp); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
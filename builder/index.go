@@ -2,7 +2,6 @@ package builder
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 
@@ -339,27 +338,6 @@ func (b *IndexBuilder) Debug() *IndexBuilder {
 	return b
 }
 
-// printDebug 打印请求调试信息
-func (b *IndexBuilder) printDebug(method, path string, body interface{}) {
-	fmt.Printf("\n[ES Debug] %s %s\n", method, path)
-	if body != nil {
-		data, _ := json.MarshalIndent(body, "", "  ")
-		fmt.Printf("Request Body:\n%s\n", string(data))
-	}
-}
-
-// printResponse 打印响应调试信息
-func (b *IndexBuilder) printResponse(respBody []byte) {
-	if len(respBody) == 0 {
-		fmt.Printf("Response: (empty)\n\n")
-		return
-	}
-	var pretty interface{}
-	json.Unmarshal(respBody, &pretty)
-	data, _ := json.MarshalIndent(pretty, "", "  ")
-	fmt.Printf("Response:\n%s\n\n", string(data))
-}
-
 // resetDebug 执行后重置debug标志（让每次调用可以独立控制）
 func (b *IndexBuilder) resetDebug() {
 	b.debug = false
@@ -370,22 +348,17 @@ func (b *IndexBuilder) Create(ctx context.Context) error {
 	path := fmt.Sprintf("/%s", b.index)
 	body := b.Build()
 
-	// 如果启用调试模式，打印请求信息
+	// 如果启用调试模式，由客户端打印请求和响应信息
 	if b.debug {
-		b.printDebug("PUT", path, body)
+		ctx = client.WithDebug(ctx)
 		defer b.resetDebug()
 	}
 
-	respBody, err := b.client.Do(ctx, http.MethodPut, path, body)
+	_, err := b.client.Do(ctx, http.MethodPut, path, body)
 	if err != nil {
 		return err
 	}
 
-	// 如果启用调试模式，打印响应信息
-	if b.debug {
-		b.printResponse(respBody)
-	}
-
 	return nil
 }
 
@@ -401,22 +374,17 @@ func (b *IndexBuilder) UpdateSettings(ctx context.Context) error {
 		"settings": b.settings,
 	}
 
-	// 如果启用调试模式，打印请求信息
+	// 如果启用调试模式，由客户端打印请求和响应信息
 	if b.debug {
-		b.printDebug("PUT", path, body)
+		ctx = client.WithDebug(ctx)
 		defer b.resetDebug()
 	}
 
-	respBody, err := b.client.Do(ctx, http.MethodPut, path, body)
+	_, err := b.client.Do(ctx, http.MethodPut, path, body)
 	if err != nil {
 		return err
 	}
 
-	// 如果启用调试模式，打印响应信息
-	if b.debug {
-		b.printResponse(respBody)
-	}
-
 	return nil
 }
 
@@ -424,22 +392,17 @@ func (b *IndexBuilder) UpdateSettings(ctx context.Context) error {
 func (b *IndexBuilder) PutMapping(ctx context.Context) error {
 	path := fmt.Sprintf("/%s/_mapping", b.index)
 
-	// 如果启用调试模式，打印请求信息
+	// 如果启用调试模式，由客户端打印请求和响应信息
 	if b.debug {
-		b.printDebug("PUT", path, b.mappings)
+		ctx = client.WithDebug(ctx)
 		defer b.resetDebug()
 	}
 
-	respBody, err := b.client.Do(ctx, http.MethodPut, path, b.mappings)
+	_, err := b.client.Do(ctx, http.MethodPut, path, b.mappings)
 	if err != nil {
 		return err
 	}
 
-	// 如果启用调试模式，打印响应信息
-	if b.debug {
-		b.printResponse(respBody)
-	}
-
 	return nil
 }
 
@@ -447,22 +410,17 @@ func (b *IndexBuilder) PutMapping(ctx context.Context) error {
 func (b *IndexBuilder) Delete(ctx context.Context) error {
 	path := fmt.Sprintf("/%s", b.index)
 
-	// 如果启用调试模式，打印请求信息
+	// 如果启用调试模式，由客户端打印请求和响应信息
 	if b.debug {
-		b.printDebug("DELETE", path, nil)
+		ctx = client.WithDebug(ctx)
 		defer b.resetDebug()
 	}
 
-	respBody, err := b.client.Do(ctx, http.MethodDelete, path, nil)
+	_, err := b.client.Do(ctx, http.MethodDelete, path, nil)
 	if err != nil {
 		return err
 	}
 
-	// 如果启用调试模式，打印响应信息
-	if b.debug {
-		b.printResponse(respBody)
-	}
-
 	return nil
 }
 
@@ -487,9 +445,9 @@ type IndexInfo struct {
 func (b *IndexBuilder) Get(ctx context.Context) (*IndexInfo, error) {
 	path := fmt.Sprintf("/%s", b.index)
 
-	// 如果启用调试模式，打印请求信息
+	// 如果启用调试模式，由客户端打印请求和响应信息
 	if b.debug {
-		b.printDebug("GET", path, nil)
+		ctx = client.WithDebug(ctx)
 		defer b.resetDebug()
 	}
 
@@ -498,13 +456,8 @@ func (b *IndexBuilder) Get(ctx context.Context) (*IndexInfo, error) {
 		return nil, err
 	}
 
-	// 如果启用调试模式，打印响应信息
-	if b.debug {
-		b.printResponse(respBody)
-	}
-
 	var result map[string]*IndexInfo
-	if err := json.Unmarshal(respBody, &result); err != nil {
+	if err := b.client.Codec().Unmarshal(respBody, &result); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
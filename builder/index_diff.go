@@ -0,0 +1,314 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Kirby980/go-es/errors"
+)
+
+// ChangeKind 变更类型
+type ChangeKind string
+
+const (
+	ChangeFieldAdded       ChangeKind = "field_added"        // 新增字段（包括子字段、对象属性）
+	ChangeFieldTypeChanged ChangeKind = "field_type_changed" // 字段类型冲突
+	ChangeAnalyzerChanged  ChangeKind = "analyzer_changed"   // 字段分析器变更
+	ChangeFieldOptionDrift ChangeKind = "field_option_drift" // 字段其他参数变更（如 ignore_above、format）
+	ChangeSettingDrift     ChangeKind = "setting_drift"      // 索引设置不一致
+)
+
+// ChangeApply 变更的应用方式
+type ChangeApply string
+
+const (
+	ApplyPutMapping     ChangeApply = "put_mapping"     // 通过 PutMapping 原地更新
+	ApplyUpdateSettings ChangeApply = "update_settings" // 通过 UpdateSettings 原地更新
+	ApplyReindex        ChangeApply = "reindex"         // 需要新建索引并重建数据
+)
+
+// IndexChange 索引定义与线上索引之间的一处差异
+type IndexChange struct {
+	Kind    ChangeKind
+	Path    string      // 字段路径（如 title.keyword）或设置名（如 number_of_replicas）
+	Current interface{} // 线上索引中的值，新增时为 nil
+	Desired interface{} // 定义中的值
+	Apply   ChangeApply
+	Reason  string
+}
+
+// InPlace 是否可以原地应用（PutMapping/UpdateSettings）
+func (c IndexChange) InPlace() bool {
+	return c.Apply != ApplyReindex
+}
+
+// String 返回变更描述
+func (c IndexChange) String() string {
+	if c.Current == nil {
+		return fmt.Sprintf("[%s] %s %s: %v (%s)", c.Apply, c.Kind, c.Path, c.Desired, c.Reason)
+	}
+	return fmt.Sprintf("[%s] %s %s: %v -> %v (%s)", c.Apply, c.Kind, c.Path, c.Current, c.Desired, c.Reason)
+}
+
+// IndexDiff 索引定义与线上索引的差异
+type IndexDiff struct {
+	Index   string
+	Missing bool // 线上索引不存在，直接 Create 即可
+	Changes []IndexChange
+}
+
+// HasChanges 是否存在差异
+func (d *IndexDiff) HasChanges() bool {
+	return d.Missing || len(d.Changes) > 0
+}
+
+// NeedsReindex 是否存在必须重建索引才能应用的变更
+func (d *IndexDiff) NeedsReindex() bool {
+	for _, c := range d.Changes {
+		if !c.InPlace() {
+			return true
+		}
+	}
+	return false
+}
+
+// InPlaceChanges 返回可以原地应用的变更
+func (d *IndexDiff) InPlaceChanges() []IndexChange {
+	return d.filter(true)
+}
+
+// ReindexChanges 返回需要重建索引的变更
+func (d *IndexDiff) ReindexChanges() []IndexChange {
+	return d.filter(false)
+}
+
+func (d *IndexDiff) filter(inPlace bool) []IndexChange {
+	var changes []IndexChange
+	for _, c := range d.Changes {
+		if c.InPlace() == inPlace {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// String 返回差异报告，每行一处变更
+func (d *IndexDiff) String() string {
+	if d.Missing {
+		return fmt.Sprintf("索引 %s 不存在", d.Index)
+	}
+	if len(d.Changes) == 0 {
+		return fmt.Sprintf("索引 %s 与定义一致", d.Index)
+	}
+	lines := make([]string, 0, len(d.Changes)+1)
+	lines = append(lines, fmt.Sprintf("索引 %s 共 %d 处差异:", d.Index, len(d.Changes)))
+	for _, c := range d.Changes {
+		lines = append(lines, "  "+c.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Diff 对比构建器中的映射/设置与线上索引，返回差异及每处差异的应用方式
+// 只对比定义中出现的字段和设置，线上多出的字段（如动态映射生成的字段）不视为差异
+//
+// 示例：
+//
+//	diff, err := builder.NewIndexBuilder(client, "products").
+//	    MappingFromStruct(Product{}).
+//	    Diff(ctx)
+//	if diff.NeedsReindex() {
+//	    log.Fatal(diff)
+//	}
+func (b *IndexBuilder) Diff(ctx context.Context) (*IndexDiff, error) {
+	diff := &IndexDiff{Index: b.index}
+
+	info, err := b.Get(ctx)
+	if err != nil {
+		if esErr, ok := err.(*errors.ESError); ok && esErr.IsNotFound() {
+			diff.Missing = true
+			return diff, nil
+		}
+		return nil, fmt.Errorf("获取索引信息失败: %w", err)
+	}
+
+	desired, _ := b.mappings["properties"].(map[string]interface{})
+	current, _ := info.Mappings["properties"].(map[string]interface{})
+	diff.Changes = append(diff.Changes, diffProperties("", current, desired)...)
+	diff.Changes = append(diff.Changes, diffSettings(info.Settings, b.settings)...)
+
+	sort.SliceStable(diff.Changes, func(i, j int) bool {
+		return diff.Changes[i].Path < diff.Changes[j].Path
+	})
+	return diff, nil
+}
+
+// inPlaceFieldOptions 可以通过 PutMapping 更新的字段参数
+var inPlaceFieldOptions = map[string]bool{
+	"search_analyzer":       true,
+	"search_quote_analyzer": true,
+	"ignore_above":          true,
+	"ignore_malformed":      true,
+	"meta":                  true,
+}
+
+// diffProperties 递归对比字段映射
+func diffProperties(prefix string, current, desired map[string]interface{}) []IndexChange {
+	var changes []IndexChange
+	for name, value := range desired {
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+		want, _ := value.(map[string]interface{})
+		have, exists := current[name].(map[string]interface{})
+		if !exists {
+			changes = append(changes, IndexChange{
+				Kind:    ChangeFieldAdded,
+				Path:    path,
+				Desired: fieldType(want),
+				Apply:   ApplyPutMapping,
+				Reason:  "新增字段可以直接添加",
+			})
+			continue
+		}
+		changes = append(changes, diffField(path, have, want)...)
+	}
+	return changes
+}
+
+// diffField 对比单个字段的映射
+func diffField(path string, current, desired map[string]interface{}) []IndexChange {
+	if have, want := fieldType(current), fieldType(desired); have != want {
+		// 类型不同时其他参数已无对比意义
+		return []IndexChange{{
+			Kind:    ChangeFieldTypeChanged,
+			Path:    path,
+			Current: have,
+			Desired: want,
+			Apply:   ApplyReindex,
+			Reason:  "已有字段的类型不能修改",
+		}}
+	}
+
+	var changes []IndexChange
+	for key, want := range desired {
+		switch key {
+		case "type":
+		case "properties", "fields":
+			wantProps, _ := want.(map[string]interface{})
+			haveProps, _ := current[key].(map[string]interface{})
+			changes = append(changes, diffProperties(path, haveProps, wantProps)...)
+		default:
+			have, exists := current[key]
+			if exists && sameValue(have, want) {
+				continue
+			}
+			change := IndexChange{
+				Kind:    ChangeFieldOptionDrift,
+				Path:    path + "." + key,
+				Current: have,
+				Desired: want,
+				Apply:   ApplyReindex,
+				Reason:  "该参数不能在已有字段上修改",
+			}
+			if key == "analyzer" {
+				change.Kind = ChangeAnalyzerChanged
+				change.Reason = "已索引的数据使用原分析器分词"
+			}
+			if inPlaceFieldOptions[key] {
+				change.Apply = ApplyPutMapping
+				change.Reason = "该参数可以在已有字段上更新"
+			}
+			changes = append(changes, change)
+		}
+	}
+	return changes
+}
+
+// fieldType 返回字段类型，有 properties 而未指定类型的字段为 object（ES 返回的映射中 object 类型会省略 type）
+func fieldType(field map[string]interface{}) string {
+	if t, ok := field["type"].(string); ok && t != "" {
+		return t
+	}
+	if _, ok := field["properties"]; ok {
+		return "object"
+	}
+	return ""
+}
+
+// staticSettingPrefixes 不能在已有索引上修改的设置
+// analysis 虽然可以在关闭索引后修改，但已索引的数据不会按新分析器重新分词，同样按需要重建处理
+var staticSettingPrefixes = []string{
+	"number_of_shards",
+	"number_of_routing_shards",
+	"routing_partition_size",
+	"codec",
+	"sort.",
+	"store.",
+	"analysis.",
+}
+
+// diffSettings 对比索引设置
+func diffSettings(current, desired map[string]interface{}) []IndexChange {
+	have := make(map[string]interface{})
+	flattenSettings("", current, have)
+	want := make(map[string]interface{})
+	flattenSettings("", desired, want)
+
+	var changes []IndexChange
+	for key, value := range want {
+		currentValue, exists := have[key]
+		if exists && sameValue(currentValue, value) {
+			continue
+		}
+		change := IndexChange{
+			Kind:    ChangeSettingDrift,
+			Path:    key,
+			Current: currentValue,
+			Desired: value,
+			Apply:   ApplyUpdateSettings,
+			Reason:  "动态设置可以直接更新",
+		}
+		for _, prefix := range staticSettingPrefixes {
+			if strings.HasPrefix(key, prefix) {
+				change.Apply = ApplyReindex
+				change.Reason = "静态设置不能在已有索引上修改"
+				break
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// flattenSettings 将嵌套设置展开为点分隔的键，并去掉 index. 前缀
+// 线上索引返回 {"index": {"number_of_shards": "1"}}，构建器中为 {"number_of_shards": 1}
+func flattenSettings(prefix string, settings map[string]interface{}, out map[string]interface{}) {
+	for key, value := range settings {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		path = strings.TrimPrefix(path, "index.")
+		if path == "index" {
+			path = ""
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			flattenSettings(path, nested, out)
+			continue
+		}
+		out[path] = value
+	}
+}
+
+// sameValue 比较线上值和定义值
+// 线上设置均以字符串返回，数字可能被解码为 float64 或 json.Number，因此按字符串形式比较
+func sameValue(current, desired interface{}) bool {
+	if reflect.DeepEqual(current, desired) {
+		return true
+	}
+	return fmt.Sprint(current) == fmt.Sprint(desired)
+}
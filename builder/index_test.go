@@ -406,6 +406,159 @@ func TestIndexBuilder_WithFields(t *testing.T) {
 	// }()
 }
 
+// mappingTestComment 评论（结构体切片生成 nested 映射）
+type mappingTestComment struct {
+	Content string `json:"content"`
+	Likes   int32  `json:"likes"`
+}
+
+// mappingTestDoc 根据结构体生成映射的测试文档
+type mappingTestDoc struct {
+	ID       int64                `json:"id"`
+	Title    string               `json:"title" es:"type=text,analyzer=standard,fields=keyword:keyword:256"`
+	Tags     []string             `json:"tags" es:"keyword"`
+	Price    float64              `json:"price"`
+	OnSale   bool                 `json:"on_sale"`
+	Comments []mappingTestComment `json:"comments"`
+	Author   struct {
+		Name string `json:"name" es:"keyword"`
+	} `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+	Internal  string    `json:"internal" es:"-"`
+}
+
+// TestIndexBuilder_MappingFromStruct 测试根据结构体标签生成映射
+func TestIndexBuilder_MappingFromStruct(t *testing.T) {
+	client := createTestClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	indexName := "test_index_mapping_from_struct"
+	_ = NewIndexBuilder(client, indexName).Delete(ctx)
+
+	builder := NewIndexBuilder(client, indexName).
+		Shards(1).
+		Replicas(0).
+		MappingFromStruct(mappingTestDoc{})
+
+	properties := builder.Build()["mappings"].(map[string]interface{})["properties"].(map[string]interface{})
+	expected := map[string]string{
+		"id":         "long",
+		"title":      "text",
+		"tags":       "keyword",
+		"price":      "double",
+		"on_sale":    "boolean",
+		"comments":   "nested",
+		"author":     "object",
+		"created_at": "date",
+	}
+	for name, fieldType := range expected {
+		field, ok := properties[name].(map[string]interface{})
+		if !ok {
+			t.Fatalf("缺少字段 %s 的映射", name)
+		}
+		if field["type"] != fieldType {
+			t.Errorf("字段 %s 类型应该是 %s，实际是 %v", name, fieldType, field["type"])
+		}
+	}
+	if _, ok := properties["internal"]; ok {
+		t.Error("es:\"-\" 字段不应该生成映射")
+	}
+	keyword := properties["title"].(map[string]interface{})["fields"].(map[string]interface{})["keyword"].(map[string]interface{})
+	if keyword["type"] != "keyword" || keyword["ignore_above"] != 256 {
+		t.Errorf("title.keyword 子字段不正确: %v", keyword)
+	}
+
+	if err := builder.Create(ctx); err != nil {
+		t.Fatalf("创建索引失败: %v", err)
+	}
+	defer func() {
+		_ = NewIndexBuilder(client, indexName).Delete(ctx)
+	}()
+
+	info, err := NewIndexBuilder(client, indexName).Get(ctx)
+	if err != nil {
+		t.Fatalf("获取索引信息失败: %v", err)
+	}
+	t.Logf("✓ 根据结构体生成映射成功:\n%s", info.PrettyJSON())
+}
+
+// TestIndexBuilder_Diff 测试对比索引定义与线上索引
+func TestIndexBuilder_Diff(t *testing.T) {
+	client := createTestClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	indexName := "test_index_diff"
+	_ = NewIndexBuilder(client, indexName).Delete(ctx)
+
+	diff, err := NewIndexBuilder(client, indexName).AddProperty("title", "text").Diff(ctx)
+	if err != nil {
+		t.Fatalf("对比不存在的索引失败: %v", err)
+	}
+	if !diff.Missing {
+		t.Error("索引不存在时 Missing 应该为 true")
+	}
+
+	err = NewIndexBuilder(client, indexName).
+		Shards(1).
+		Replicas(0).
+		AddProperty("title", "text", WithSubField("keyword", "keyword", WithIgnoreAbove(256))).
+		AddProperty("price", "float").
+		Create(ctx)
+	if err != nil {
+		t.Fatalf("创建索引失败: %v", err)
+	}
+	defer func() {
+		_ = NewIndexBuilder(client, indexName).Delete(ctx)
+	}()
+
+	// 定义与线上一致
+	diff, err = NewIndexBuilder(client, indexName).
+		Shards(1).
+		Replicas(0).
+		AddProperty("title", "text", WithSubField("keyword", "keyword", WithIgnoreAbove(256))).
+		AddProperty("price", "float").
+		Diff(ctx)
+	if err != nil {
+		t.Fatalf("对比索引失败: %v", err)
+	}
+	if diff.HasChanges() {
+		t.Errorf("定义与线上一致时不应该有差异:\n%s", diff)
+	}
+
+	// 新增字段、修改副本数可以原地应用，修改字段类型需要重建索引
+	diff, err = NewIndexBuilder(client, indexName).
+		Replicas(1).
+		AddProperty("title", "text", WithSubField("keyword", "keyword", WithIgnoreAbove(512))).
+		AddProperty("price", "double").
+		AddProperty("stock", "integer").
+		Diff(ctx)
+	if err != nil {
+		t.Fatalf("对比索引失败: %v", err)
+	}
+	t.Logf("差异报告:\n%s", diff)
+
+	applies := make(map[string]ChangeApply)
+	for _, c := range diff.Changes {
+		applies[c.Path] = c.Apply
+	}
+	expected := map[string]ChangeApply{
+		"stock":                      ApplyPutMapping,
+		"title.keyword.ignore_above": ApplyPutMapping,
+		"number_of_replicas":         ApplyUpdateSettings,
+		"price":                      ApplyReindex,
+	}
+	for path, apply := range expected {
+		if applies[path] != apply {
+			t.Errorf("%s 的应用方式应该是 %s，实际是 %s", path, apply, applies[path])
+		}
+	}
+	if !diff.NeedsReindex() {
+		t.Error("修改字段类型应该需要重建索引")
+	}
+}
+
 // TestIndexBuilder_MultiplePropertyOptions 测试组合使用多个 PropertyOption
 func TestIndexBuilder_MultiplePropertyOptions(t *testing.T) {
 	client := createTestClient(t)
@@ -0,0 +1,136 @@
+package builder
+
+import (
+	"context"
+	"iter"
+)
+
+// ========== 迭代器 ==========
+//
+// All 返回 range-over-func 迭代器，自动完成翻页和资源清理：
+//
+//	for hit, err := range builder.NewScrollBuilder(client, "orders").Size(1000).All(ctx) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    process(hit)
+//	}
+//
+// 循环正常结束、break 或 ctx 结束时都会清除 scroll 上下文（或关闭 PIT）。
+// 出错时迭代器产出一次错误后结束。
+
+// All 遍历所有匹配的文档
+// 设置 Slices 时各个切片并发读取，命中仍然在调用方的 goroutine 中依次产出
+func (b *ScrollBuilder) All(ctx context.Context) iter.Seq2[Hit, error] {
+	return scrollAll[map[string]interface{}](ctx, b)
+}
+
+// ScrollAllAs 遍历所有匹配的文档，产出解码为 T 的 _source
+func ScrollAllAs[T any](ctx context.Context, b *ScrollBuilder) iter.Seq2[T, error] {
+	return hitSources(scrollAll[T](ctx, b))
+}
+
+// All 从当前位置开始逐页遍历所有匹配的文档
+// 结束时关闭 OpenPIT 打开的 PIT；通过 PIT 方法传入的共享 PIT 由调用方关闭
+func (b *SearchAfterBuilder) All(ctx context.Context) iter.Seq2[Hit, error] {
+	return searchAfterAll[map[string]interface{}](ctx, b)
+}
+
+// SearchAfterAllAs 逐页遍历所有匹配的文档，产出解码为 T 的 _source
+func SearchAfterAllAs[T any](ctx context.Context, b *SearchAfterBuilder) iter.Seq2[T, error] {
+	return hitSources(searchAfterAll[T](ctx, b))
+}
+
+// scrollAll 在后台 goroutine 中执行 EachAs，通过通道把命中交给迭代器
+// 迭代提前结束时取消遍历，并等待 scroll 上下文清除后再返回
+func scrollAll[T any](ctx context.Context, b *ScrollBuilder) iter.Seq2[TypedHit[T], error] {
+	return func(yield func(TypedHit[T], error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		hits := make(chan TypedHit[T])
+		done := make(chan error, 1)
+		go func() {
+			defer close(hits)
+			done <- EachAs(ctx, b, func(hit TypedHit[T]) error {
+				select {
+				case hits <- hit:
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})
+		}()
+
+		for hit := range hits {
+			if !yield(hit, nil) {
+				cancel()
+				for range hits {
+					// 丢弃已经读取的命中，等待遍历结束
+				}
+				<-done
+				return
+			}
+		}
+
+		if err := <-done; err != nil {
+			var zero TypedHit[T]
+			yield(zero, err)
+		}
+	}
+}
+
+// searchAfterAll 在调用方的 goroutine 中逐页查询
+func searchAfterAll[T any](ctx context.Context, b *SearchAfterBuilder) iter.Seq2[TypedHit[T], error] {
+	return func(yield func(TypedHit[T], error) bool) {
+		var zero TypedHit[T]
+		// 迭代正常结束（没有 break 或 panic）时才能继续调用 yield
+		finished := false
+
+		// 关闭由构建器打开的 PIT（ctx 已经取消时仍然需要关闭）
+		if b.ownsPIT {
+			defer func() {
+				closeCtx, cancel := cleanupContext(ctx)
+				defer cancel()
+				if err := b.ClosePIT(closeCtx); err != nil && finished {
+					yield(zero, err)
+				}
+			}()
+		}
+
+		resp, err := SearchAfterAs[T](ctx, b)
+		for {
+			if err != nil {
+				finished = yield(zero, err)
+				return
+			}
+			if len(resp.Hits.Hits) == 0 {
+				finished = true
+				return
+			}
+
+			for _, hit := range resp.Hits.Hits {
+				if err := ctx.Err(); err != nil {
+					finished = yield(zero, err)
+					return
+				}
+				if !yield(hit, nil) {
+					return
+				}
+			}
+
+			resp, err = SearchAfterNextAs[T](ctx, b)
+		}
+	}
+}
+
+// hitSources 把命中迭代器转换为 _source 迭代器
+func hitSources[T any](seq iter.Seq2[TypedHit[T], error]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for hit, err := range seq {
+			if !yield(hit.Source, err) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package builder
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MappingFromStruct 根据结构体生成字段映射（合并到已有的 AddProperty 定义中，同名字段以结构体为准）
+//
+// 字段名取自 json 标签（与 SetStruct 写入的字段一致），类型根据 Go 类型推断，可以通过 es 标签覆盖：
+//
+//	type Article struct {
+//	    ID        int64     `json:"id"`                                                   // long
+//	    Title     string    `json:"title" es:"type=text,analyzer=ik_max_word,fields=keyword:keyword:256"`
+//	    Tags      []string  `json:"tags" es:"type=keyword,ignore_above=128"`
+//	    Comments  []Comment `json:"comments"`                                             // nested
+//	    Author    Author    `json:"author"`                                               // object
+//	    CreatedAt time.Time `json:"created_at" es:"format=strict_date_optional_time"`     // date
+//	    Internal  string    `json:"internal" es:"-"`                                      // 不生成映射
+//	}
+//
+// es 标签选项：
+//   - type=xxx: 字段类型，单独的 "keyword" 等价于 type=keyword
+//   - fields=名称:类型[:ignore_above]: 子字段（对应 WithSubField），多个子字段用 | 分隔
+//   - 其他 key=value（analyzer、search_analyzer、format、index、store、ignore_above、copy_to 等）原样写入映射
+func (b *IndexBuilder) MappingFromStruct(v any) *IndexBuilder {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return b
+	}
+
+	if b.mappings["properties"] == nil {
+		b.mappings["properties"] = make(map[string]interface{})
+	}
+	properties := b.mappings["properties"].(map[string]interface{})
+	for name, field := range structProperties(t, map[reflect.Type]bool{}) {
+		properties[name] = field
+	}
+	return b
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// structProperties 生成结构体所有字段的映射
+// visiting 记录正在处理的结构体类型，避免自引用类型无限递归
+func structProperties(t reflect.Type, visiting map[reflect.Type]bool) map[string]interface{} {
+	properties := make(map[string]interface{})
+	if visiting[t] {
+		return properties
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+
+		name, skip := jsonFieldName(f)
+		if skip {
+			continue
+		}
+
+		esTag := f.Tag.Get("es")
+		if esTag == "-" {
+			continue
+		}
+
+		// 没有 json 名称的嵌入结构体，字段提升到外层（与 encoding/json 一致）
+		ft := derefType(f.Type)
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct && esTag == "" {
+			for k, v := range structProperties(ft, visiting) {
+				if _, exists := properties[k]; !exists {
+					properties[k] = v
+				}
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		if field := fieldMapping(f.Type, esTag, visiting); field != nil {
+			properties[name] = field
+		}
+	}
+	return properties
+}
+
+// fieldMapping 生成单个字段的映射，无法推断类型时返回 nil（交给 ES 动态映射）
+func fieldMapping(t reflect.Type, esTag string, visiting map[reflect.Type]bool) map[string]interface{} {
+	opts := parseESTag(esTag)
+
+	field := make(map[string]interface{})
+	fieldType, explicit := opts["type"]
+	if !explicit {
+		fieldType = inferESType(t)
+	}
+	if fieldType == "" {
+		return nil
+	}
+	field["type"] = fieldType
+
+	// object/nested 类型生成子属性
+	if fieldType == "object" || fieldType == "nested" {
+		if st := structElem(t); st != nil {
+			if props := structProperties(st, visiting); len(props) > 0 {
+				field["properties"] = props
+			}
+		}
+	}
+
+	for key, value := range opts {
+		switch key {
+		case "type":
+		case "fields":
+			for _, spec := range strings.Split(value, "|") {
+				applySubField(field, spec)
+			}
+		default:
+			field[key] = parseTagValue(value)
+		}
+	}
+	return field
+}
+
+// inferESType 根据 Go 类型推断 ES 字段类型
+func inferESType(t reflect.Type) string {
+	t = derefType(t)
+	if t == timeType {
+		return "date"
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return "text"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int8:
+		return "byte"
+	case reflect.Int16, reflect.Uint8:
+		return "short"
+	case reflect.Int32, reflect.Uint16:
+		return "integer"
+	case reflect.Int, reflect.Int64, reflect.Uint32:
+		return "long"
+	case reflect.Uint, reflect.Uint64:
+		return "unsigned_long"
+	case reflect.Float32:
+		return "float"
+	case reflect.Float64:
+		return "double"
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		elem := derefType(t.Elem())
+		if elem.Kind() == reflect.Uint8 {
+			return "binary"
+		}
+		// 结构体切片使用 nested，保证数组中每个对象的字段关联关系
+		if elem.Kind() == reflect.Struct && elem != timeType {
+			return "nested"
+		}
+		// 普通数组与元素类型相同
+		return inferESType(elem)
+	}
+	return ""
+}
+
+// structElem 返回结构体类型（或结构体切片的元素类型）
+func structElem(t reflect.Type) reflect.Type {
+	t = derefType(t)
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = derefType(t.Elem())
+	}
+	if t.Kind() == reflect.Struct && t != timeType {
+		return t
+	}
+	return nil
+}
+
+// applySubField 解析 "名称:类型[:ignore_above]" 并添加子字段
+func applySubField(field map[string]interface{}, spec string) {
+	parts := strings.Split(spec, ":")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return
+	}
+
+	var options []PropertyOption
+	if len(parts) > 2 {
+		if limit, err := strconv.Atoi(parts[2]); err == nil {
+			options = append(options, WithIgnoreAbove(limit))
+		}
+	}
+	WithSubField(parts[0], parts[1], options...)(field)
+}
+
+// parseESTag 解析 es 标签
+func parseESTag(tag string) map[string]string {
+	opts := make(map[string]string)
+	if tag == "" {
+		return opts
+	}
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			// 单独的值视为字段类型，如 es:"keyword"
+			key, value = "type", part
+		}
+		opts[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return opts
+}
+
+// parseTagValue 将标签值转换为布尔值或整数，其余保持字符串
+func parseTagValue(value string) interface{} {
+	if b, err := strconv.ParseBool(value); err == nil {
+		return b
+	}
+	if n, err := strconv.Atoi(value); err == nil {
+		return n
+	}
+	return value
+}
+
+// jsonFieldName 返回 json 标签中的字段名，json:"-" 时 skip 为 true
+func jsonFieldName(f reflect.StructField) (name string, skip bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+	name, _, _ = strings.Cut(tag, ",")
+	return name, false
+}
+
+// derefType 去掉指针
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
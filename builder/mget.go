@@ -2,7 +2,6 @@ package builder
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 
@@ -14,6 +13,7 @@ type MGetBuilder struct {
 	client *client.Client
 	index  string
 	ids    []string
+	debug  bool
 }
 
 // NewMGetBuilder 创建批量获取构建器
@@ -31,6 +31,17 @@ func (b *MGetBuilder) IDs(ids ...string) *MGetBuilder {
 	return b
 }
 
+// Debug 启用调试模式（链式调用）
+func (b *MGetBuilder) Debug() *MGetBuilder {
+	b.debug = true
+	return b
+}
+
+// resetDebug 执行后重置debug标志（让每次调用可以独立控制）
+func (b *MGetBuilder) resetDebug() {
+	b.debug = false
+}
+
 // MGetResponse 批量获取响应
 type MGetResponse struct {
 	Docs []GetResponse `json:"docs"`
@@ -43,13 +54,19 @@ func (b *MGetBuilder) Do(ctx context.Context) (*MGetResponse, error) {
 		"ids": b.ids,
 	}
 
+	// 如果启用调试模式，由客户端打印请求和响应信息
+	if b.debug {
+		ctx = client.WithDebug(ctx)
+		defer b.resetDebug()
+	}
+
 	respBody, err := b.client.Do(ctx, http.MethodPost, path, body)
 	if err != nil {
 		return nil, err
 	}
 
 	var resp MGetResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := b.client.Codec().Unmarshal(respBody, &resp); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
@@ -0,0 +1,272 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kirby980/go-es/client"
+	"github.com/Kirby980/go-es/errors"
+	"github.com/Kirby980/go-es/query"
+)
+
+// Migrator 基于别名的零停机索引迁移
+//
+// 业务通过别名（如 products）读写，实际索引按版本命名（products_v1、products_v2...）。
+// Run 会依次执行：
+//  1. 根据新定义创建 {alias}_v{N+1}
+//  2. 从当前版本异步 _reindex 并跟踪任务直到完成
+//  3. （可选）按时间字段补齐重建期间写入的文档
+//  4. 通过 _aliases 原子地将别名切换到新索引
+//  5. （可选）删除旧索引
+//
+// 别名不存在时视为首次部署，直接创建 {alias}_v1 并添加别名。
+// 已经存在与别名同名的索引时（直接使用索引名读写的旧部署），需要调用 ReplaceIndex 才会迁移。
+type Migrator struct {
+	client       *client.Client
+	alias        string
+	definition   *IndexBuilder
+	catchUpField string
+	catchUpLag   time.Duration
+	deleteOld    bool
+	replaceIndex bool
+	pollInterval time.Duration
+}
+
+// NewMigrator 创建迁移器，definition 为新版本的索引定义（其中的索引名会被忽略）
+func NewMigrator(c *client.Client, alias string, definition *IndexBuilder) *Migrator {
+	return &Migrator{
+		client:       c,
+		alias:        alias,
+		definition:   definition,
+		pollInterval: time.Second,
+	}
+}
+
+// CatchUp 重建完成后，再次重建 field 不早于“开始时间 - overlap”的文档，补齐重建期间写入的数据
+// field 必须是写入时更新的日期字段（如 updated_at）；overlap 用于覆盖刷新间隔和时钟误差
+// 注意：重建期间删除的文档不会同步到新索引
+func (m *Migrator) CatchUp(field string, overlap time.Duration) *Migrator {
+	m.catchUpField = field
+	m.catchUpLag = overlap
+	return m
+}
+
+// DeleteOld 切换别名后删除旧索引
+func (m *Migrator) DeleteOld() *Migrator {
+	m.deleteOld = true
+	return m
+}
+
+// ReplaceIndex 允许迁移与别名同名的索引
+// 该索引的数据重建到 {alias}_v1 后，在切换别名的同一个 _aliases 请求中删除该索引并添加别名
+// 别名不能与索引同名，因此无论是否调用 DeleteOld，该索引都会被删除
+func (m *Migrator) ReplaceIndex() *Migrator {
+	m.replaceIndex = true
+	return m
+}
+
+// PollInterval 设置重建任务的轮询间隔（默认 1 秒）
+func (m *Migrator) PollInterval(interval time.Duration) *Migrator {
+	m.pollInterval = interval
+	return m
+}
+
+// MigrationResult 迁移结果
+type MigrationResult struct {
+	OldIndex   string // 迁移前别名指向的索引（或与别名同名的索引），首次部署时为空
+	NewIndex   string // 新建的索引
+	Reindexed  int64  // 重建的文档数
+	CaughtUp   int64  // 补齐阶段重建的文档数
+	OldDeleted bool   // 是否已删除旧索引
+}
+
+// Run 执行迁移
+// 失败时不会切换别名，已创建的新索引会保留以便排查，重新执行前需要先删除
+func (m *Migrator) Run(ctx context.Context) (*MigrationResult, error) {
+	current, concrete, err := m.currentIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if concrete && !m.replaceIndex {
+		return nil, fmt.Errorf("索引 %s 已存在且与别名同名，无法添加别名；调用 ReplaceIndex 将其迁移到 %s_v1", m.alias, m.alias)
+	}
+
+	result := &MigrationResult{
+		OldIndex: current,
+		NewIndex: fmt.Sprintf("%s_v%d", m.alias, indexVersion(current, m.alias)+1),
+	}
+
+	if err := m.createIndex(ctx, result.NewIndex); err != nil {
+		return result, fmt.Errorf("创建索引 %s 失败: %w", result.NewIndex, err)
+	}
+
+	if current != "" {
+		start := time.Now()
+
+		reindexed, err := m.reindex(ctx, NewReindexBuilder(m.client).Source(current).Dest(result.NewIndex))
+		if err != nil {
+			return result, fmt.Errorf("从 %s 重建索引失败: %w", current, err)
+		}
+		result.Reindexed = reindexed
+
+		if m.catchUpField != "" {
+			// 以 epoch_millis 指定起始时间，与字段 mapping 中的日期格式无关
+			since := query.Range(m.catchUpField).
+				Gte(start.Add(-m.catchUpLag).UnixMilli()).
+				Format("epoch_millis")
+			caughtUp, err := m.reindex(ctx, NewReindexBuilder(m.client).
+				Source(current).
+				Dest(result.NewIndex).
+				Query(since))
+			if err != nil {
+				return result, fmt.Errorf("补齐重建期间写入的文档失败: %w", err)
+			}
+			result.CaughtUp = caughtUp
+		}
+	}
+
+	// 刷新新索引，保证切换别名后立即可以搜索到全部文档
+	if _, err := m.client.Do(ctx, http.MethodPost, fmt.Sprintf("/%s/_refresh", result.NewIndex), nil); err != nil {
+		return result, fmt.Errorf("刷新索引 %s 失败: %w", result.NewIndex, err)
+	}
+
+	if err := m.switchAlias(ctx, current, concrete, result.NewIndex); err != nil {
+		return result, fmt.Errorf("切换别名 %s 失败: %w", m.alias, err)
+	}
+	if concrete {
+		result.OldDeleted = true
+		return result, nil
+	}
+
+	if m.deleteOld && current != "" {
+		if err := NewIndexBuilder(m.client, current).Delete(ctx); err != nil {
+			return result, fmt.Errorf("删除旧索引 %s 失败: %w", current, err)
+		}
+		result.OldDeleted = true
+	}
+
+	return result, nil
+}
+
+// currentIndex 返回别名当前指向的索引，别名和同名索引都不存在时返回空字符串
+// concrete 表示返回的是与别名同名的索引，而不是别名
+func (m *Migrator) currentIndex(ctx context.Context) (index string, concrete bool, err error) {
+	respBody, err := m.client.Do(ctx, http.MethodGet, fmt.Sprintf("/_alias/%s", m.alias), nil)
+	if err != nil {
+		if esErr, ok := err.(*errors.ESError); ok && esErr.IsNotFound() {
+			return m.concreteIndex(ctx)
+		}
+		return "", false, fmt.Errorf("获取别名 %s 失败: %w", m.alias, err)
+	}
+
+	var result map[string]interface{}
+	if err := m.client.Codec().Unmarshal(respBody, &result); err != nil {
+		return "", false, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if len(result) != 1 {
+		return "", false, fmt.Errorf("别名 %s 指向 %d 个索引，无法确定迁移源", m.alias, len(result))
+	}
+	for index := range result {
+		return index, false, nil
+	}
+	return "", false, nil
+}
+
+// concreteIndex 检查是否存在与别名同名的索引
+func (m *Migrator) concreteIndex(ctx context.Context) (string, bool, error) {
+	_, err := m.client.Do(ctx, http.MethodHead, "/"+m.alias, nil)
+	if err != nil {
+		if esErr, ok := err.(*errors.ESError); ok && esErr.IsNotFound() {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("检查索引 %s 失败: %w", m.alias, err)
+	}
+	return m.alias, true, nil
+}
+
+// indexVersion 从 {alias}_v{N} 中解析版本号，不符合命名规则时返回 0
+func indexVersion(index, alias string) int {
+	suffix, ok := strings.CutPrefix(index, alias+"_v")
+	if !ok {
+		return 0
+	}
+	version, err := strconv.Atoi(suffix)
+	if err != nil {
+		return 0
+	}
+	return version
+}
+
+// createIndex 按定义创建新版本索引
+// 定义中与迁移别名同名的别名会被去掉，由切换步骤统一添加，避免别名同时指向新旧索引
+func (m *Migrator) createIndex(ctx context.Context, index string) error {
+	definition := *m.definition
+	definition.index = index
+	definition.aliases = make(map[string]interface{}, len(m.definition.aliases))
+	for name, alias := range m.definition.aliases {
+		if name != m.alias {
+			definition.aliases[name] = alias
+		}
+	}
+	return definition.Create(ctx)
+}
+
+// reindex 异步执行重建并等待完成，返回写入新索引的文档数
+func (m *Migrator) reindex(ctx context.Context, reindex *ReindexBuilder) (int64, error) {
+	task, err := reindex.Start(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	status, err := task.Wait(ctx, m.pollInterval)
+	if err != nil {
+		return 0, err
+	}
+
+	var resp ReindexResponse
+	if err := status.DecodeResponse(m.client, &resp); err != nil {
+		return 0, err
+	}
+	if len(resp.Failures) > 0 {
+		return 0, fmt.Errorf("%d 个文档重建失败: %v", len(resp.Failures), resp.Failures[0])
+	}
+
+	return resp.Created + resp.Updated, nil
+}
+
+// switchAlias 在一个 _aliases 请求中移除旧索引的别名并添加到新索引
+// 旧索引与别名同名时，在同一个请求中删除旧索引
+func (m *Migrator) switchAlias(ctx context.Context, oldIndex string, concrete bool, newIndex string) error {
+	actions := make([]map[string]interface{}, 0, 2)
+	switch {
+	case concrete:
+		actions = append(actions, map[string]interface{}{
+			"remove_index": map[string]interface{}{
+				"index": oldIndex,
+			},
+		})
+	case oldIndex != "":
+		actions = append(actions, map[string]interface{}{
+			"remove": map[string]interface{}{
+				"index": oldIndex,
+				"alias": m.alias,
+			},
+		})
+	}
+	actions = append(actions, map[string]interface{}{
+		"add": map[string]interface{}{
+			"index":          newIndex,
+			"alias":          m.alias,
+			"is_write_index": true,
+		},
+	})
+
+	_, err := m.client.Do(ctx, http.MethodPost, "/_aliases", map[string]interface{}{
+		"actions": actions,
+	})
+	return err
+}
@@ -0,0 +1,200 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Kirby980/go-es/client"
+	"github.com/Kirby980/go-es/config"
+)
+
+// TestMigrator_Run 测试基于别名的零停机迁移
+func TestMigrator_Run(t *testing.T) {
+	client := createTestClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	alias := "test_migrator_products"
+	for _, index := range []string{alias + "_v1", alias + "_v2"} {
+		_ = NewIndexBuilder(client, index).Delete(ctx)
+	}
+	defer func() {
+		_ = NewIndexBuilder(client, alias+"_v2").Delete(ctx)
+	}()
+
+	// 首次部署：创建 v1 并添加别名
+	v1 := NewIndexBuilder(client, alias).
+		Shards(1).
+		Replicas(0).
+		AddProperty("name", "text").
+		AddProperty("price", "float").
+		AddProperty("updated_at", "date")
+	result, err := NewMigrator(client, alias, v1).Run(ctx)
+	if err != nil {
+		t.Fatalf("首次迁移失败: %v", err)
+	}
+	if result.OldIndex != "" || result.NewIndex != alias+"_v1" {
+		t.Fatalf("首次迁移结果不正确: %+v", result)
+	}
+
+	// 通过别名写入数据
+	bulk := NewBulkBuilder(client)
+	for i := 1; i <= 20; i++ {
+		bulk.Add(alias, fmt.Sprintf("%d", i), map[string]interface{}{
+			"name":       fmt.Sprintf("product %d", i),
+			"price":      float64(i) * 10,
+			"updated_at": time.Now().Format(time.RFC3339),
+		})
+	}
+	if _, err := bulk.Do(ctx); err != nil {
+		t.Fatalf("写入数据失败: %v", err)
+	}
+	time.Sleep(2 * time.Second) // 等待索引刷新
+
+	// 修改字段类型，迁移到 v2
+	v2 := NewIndexBuilder(client, alias).
+		Shards(1).
+		Replicas(0).
+		AddProperty("name", "text", WithSubField("keyword", "keyword")).
+		AddProperty("price", "double").
+		AddProperty("updated_at", "date")
+	result, err = NewMigrator(client, alias, v2).
+		CatchUp("updated_at", time.Minute).
+		DeleteOld().
+		PollInterval(200 * time.Millisecond).
+		Run(ctx)
+	if err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	t.Logf("迁移结果: %+v", result)
+
+	if result.OldIndex != alias+"_v1" || result.NewIndex != alias+"_v2" {
+		t.Errorf("迁移索引不正确: %+v", result)
+	}
+	if result.Reindexed != 20 {
+		t.Errorf("应该重建 20 个文档，实际 %d", result.Reindexed)
+	}
+	if !result.OldDeleted {
+		t.Error("旧索引应该已删除")
+	}
+
+	// 别名指向新索引
+	count, err := NewSearchBuilder(client, alias).Count(ctx)
+	if err != nil {
+		t.Fatalf("通过别名查询失败: %v", err)
+	}
+	if count != 20 {
+		t.Errorf("通过别名应该查询到 20 个文档，实际 %d", count)
+	}
+
+	info, err := NewIndexBuilder(client, alias+"_v2").Get(ctx)
+	if err != nil {
+		t.Fatalf("获取新索引失败: %v", err)
+	}
+	if _, ok := info.Aliases[alias]; !ok {
+		t.Errorf("新索引应该包含别名 %s", alias)
+	}
+}
+
+// migratorStub 模拟 ES 的迁移相关接口：别名不存在，但存在与别名同名的索引
+type migratorStub struct {
+	mu       sync.Mutex
+	requests []string                 // 请求的方法和路径
+	reindex  []map[string]interface{} // _reindex 请求体
+	aliases  map[string]interface{}   // _aliases 请求体
+}
+
+func (s *migratorStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&body)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/_alias/"):
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"alias [products] missing","status":404}`))
+	case r.Method == http.MethodHead:
+		w.WriteHeader(http.StatusOK)
+	case r.URL.Path == "/_reindex":
+		s.reindex = append(s.reindex, body)
+		fmt.Fprintf(w, `{"task":"node:%d"}`, len(s.reindex))
+	case strings.HasPrefix(r.URL.Path, "/_tasks/"):
+		w.Write([]byte(`{"completed":true,"task":{},"response":{"created":3,"failures":[]}}`))
+	case r.URL.Path == "/_aliases":
+		s.aliases = body
+		w.Write([]byte(`{"acknowledged":true}`))
+	default:
+		w.Write([]byte(`{"acknowledged":true}`))
+	}
+}
+
+// TestMigrator_ConcreteIndex 测试存在与别名同名的索引时的迁移
+func TestMigrator_ConcreteIndex(t *testing.T) {
+	stub := &migratorStub{}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	esClient, err := client.New(
+		config.WithAddresses(server.URL),
+		config.WithLogger(slog.New(slog.DiscardHandler)),
+	)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer esClient.Close()
+	ctx := context.Background()
+
+	definition := NewIndexBuilder(esClient, "products").AddProperty("updated_at", "date")
+
+	// 未调用 ReplaceIndex 时返回明确的错误，不创建任何索引
+	_, err = NewMigrator(esClient, "products", definition).Run(ctx)
+	if err == nil || !strings.Contains(err.Error(), "ReplaceIndex") {
+		t.Fatalf("应该提示调用 ReplaceIndex，实际 %v", err)
+	}
+	for _, req := range stub.requests {
+		if strings.HasPrefix(req, http.MethodPut) {
+			t.Fatalf("未调用 ReplaceIndex 时不应该创建索引: %v", stub.requests)
+		}
+	}
+
+	// 调用 ReplaceIndex 后迁移到 products_v1，并在切换别名时删除同名索引
+	result, err := NewMigrator(esClient, "products", definition).
+		ReplaceIndex().
+		CatchUp("updated_at", time.Minute).
+		PollInterval(time.Millisecond).
+		Run(ctx)
+	if err != nil {
+		t.Fatalf("迁移失败: %v", err)
+	}
+	if result.OldIndex != "products" || result.NewIndex != "products_v1" || !result.OldDeleted {
+		t.Fatalf("迁移结果不正确: %+v", result)
+	}
+
+	actions, _ := json.Marshal(stub.aliases["actions"])
+	if want := `[{"remove_index":{"index":"products"}},{"add":{"alias":"products","index":"products_v1","is_write_index":true}}]`; string(actions) != want {
+		t.Errorf("别名操作不正确:\n实际 %s\n期望 %s", actions, want)
+	}
+
+	// 补齐阶段以 epoch_millis 指定起始时间
+	if len(stub.reindex) != 2 {
+		t.Fatalf("应该执行 2 次重建，实际 %d", len(stub.reindex))
+	}
+	catchUp, _ := json.Marshal(stub.reindex[1]["source"])
+	if !strings.Contains(string(catchUp), `"format":"epoch_millis"`) {
+		t.Errorf("补齐阶段的范围查询应该指定 epoch_millis 格式，实际 %s", catchUp)
+	}
+
+	t.Logf("✓ 同名索引迁移到 %s", result.NewIndex)
+}
@@ -0,0 +1,228 @@
+package builder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/Kirby980/go-es/client"
+	"github.com/Kirby980/go-es/errors"
+)
+
+// MultiSearchBuilder 批量搜索构建器
+// 将多个 SearchBuilder 合并为一次 _msearch 请求，减少网络往返
+type MultiSearchBuilder struct {
+	client   *client.Client
+	searches []multiSearchItem
+	params   url.Values
+	debug    bool
+}
+
+// multiSearchItem 批量搜索中的一个搜索
+type multiSearchItem struct {
+	header map[string]interface{}
+	search *SearchBuilder
+}
+
+// NewMultiSearchBuilder 创建批量搜索构建器
+func NewMultiSearchBuilder(c *client.Client) *MultiSearchBuilder {
+	return &MultiSearchBuilder{
+		client:   c,
+		searches: make([]multiSearchItem, 0),
+		params:   url.Values{},
+	}
+}
+
+// Add 添加搜索，每个搜索使用各自构建器的索引
+func (b *MultiSearchBuilder) Add(searches ...*SearchBuilder) *MultiSearchBuilder {
+	for _, search := range searches {
+		b.searches = append(b.searches, multiSearchItem{search: search})
+	}
+	return b
+}
+
+// AddWithHeader 添加搜索并设置该搜索的头部参数，如 routing、preference、search_type、request_cache
+// 索引始终使用构建器的索引（使用 PIT 时不指定索引）
+func (b *MultiSearchBuilder) AddWithHeader(search *SearchBuilder, header map[string]interface{}) *MultiSearchBuilder {
+	b.searches = append(b.searches, multiSearchItem{header: header, search: search})
+	return b
+}
+
+// MaxConcurrentSearches 设置 ES 并发执行的最大搜索数
+func (b *MultiSearchBuilder) MaxConcurrentSearches(n int) *MultiSearchBuilder {
+	b.params.Set("max_concurrent_searches", strconv.Itoa(n))
+	return b
+}
+
+// Count 返回搜索数量
+func (b *MultiSearchBuilder) Count() int {
+	return len(b.searches)
+}
+
+// Debug 启用调试模式（链式调用）
+func (b *MultiSearchBuilder) Debug() *MultiSearchBuilder {
+	b.debug = true
+	return b
+}
+
+// resetDebug 执行后重置debug标志（让每次调用可以独立控制）
+func (b *MultiSearchBuilder) resetDebug() {
+	b.debug = false
+}
+
+// Build 构建批量搜索请求体（NDJSON，每个搜索一行头部、一行查询）
+func (b *MultiSearchBuilder) Build() []byte {
+	var buf bytes.Buffer
+	b.writeTo(&buf)
+
+	return buf.Bytes()
+}
+
+// writeTo 将批量搜索以 NDJSON 格式写入 w
+func (b *MultiSearchBuilder) writeTo(w io.Writer) error {
+	codec := b.client.Codec()
+	writeLine := func(v interface{}) error {
+		line, err := codec.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			return err
+		}
+		return nil
+	}
+
+	for _, item := range b.searches {
+		// 写入头部行
+		header := make(map[string]interface{}, len(item.header)+1)
+		for k, v := range item.header {
+			header[k] = v
+		}
+		// 使用 PIT 的搜索不能指定索引
+		if item.search.pit == nil {
+			header["index"] = item.search.index
+		}
+		if err := writeLine(header); err != nil {
+			return err
+		}
+
+		// 写入查询行
+		if err := writeLine(item.search.Build()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MultiSearchError 部分搜索失败时 Do 返回的错误，成功的搜索结果仍然可用
+type MultiSearchError struct {
+	Errors []*errors.ESError // 与添加顺序一致，成功的搜索为 nil
+}
+
+func (e *MultiSearchError) Error() string {
+	failed, first := 0, -1
+	for i, err := range e.Errors {
+		if err != nil {
+			failed++
+			if first < 0 {
+				first = i
+			}
+		}
+	}
+	return fmt.Sprintf("批量搜索中 %d/%d 个搜索失败，第 %d 个: %v", failed, len(e.Errors), first+1, e.Errors[first])
+}
+
+// multiSearchResponse _msearch 响应
+type multiSearchResponse struct {
+	Took      int               `json:"took"`
+	Responses []json.RawMessage `json:"responses"`
+}
+
+// multiSearchItemStatus 单个搜索的状态，失败时包含 error
+type multiSearchItemStatus struct {
+	Status int             `json:"status"`
+	Error  json.RawMessage `json:"error"`
+}
+
+// Do 执行批量搜索，结果与添加顺序一致
+// 部分搜索失败时，失败位置的结果为 nil，同时返回 *MultiSearchError
+func (b *MultiSearchBuilder) Do(ctx context.Context) ([]*SearchResponse, error) {
+	if len(b.searches) == 0 {
+		return nil, fmt.Errorf("没有待执行的搜索")
+	}
+	for i, item := range b.searches {
+		if item.search.err != nil {
+			return nil, fmt.Errorf("第 %d 个搜索: %w", i+1, item.search.err)
+		}
+	}
+
+	var body bytes.Buffer
+	if err := b.writeTo(&body); err != nil {
+		return nil, fmt.Errorf("序列化请求体失败: %w", err)
+	}
+
+	path := requestPath("/_msearch", b.params)
+
+	// 如果启用调试模式，由客户端打印请求和响应信息
+	if b.debug {
+		ctx = client.WithDebug(ctx)
+		defer b.resetDebug()
+	}
+
+	// 创建请求，节点由客户端在执行时选择
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, path, &body)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	// 设置正确的 Content-Type
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	respBody, err := b.client.DoRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	codec := b.client.Codec()
+	var resp multiSearchResponse
+	if err := codec.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if len(resp.Responses) != len(b.searches) {
+		return nil, fmt.Errorf("响应数量 %d 与搜索数量 %d 不一致", len(resp.Responses), len(b.searches))
+	}
+
+	results := make([]*SearchResponse, len(resp.Responses))
+	var itemErrors []*errors.ESError
+	for i, raw := range resp.Responses {
+		var status multiSearchItemStatus
+		if err := codec.Unmarshal(raw, &status); err != nil {
+			return nil, fmt.Errorf("解析第 %d 个搜索的响应失败: %w", i+1, err)
+		}
+
+		// 单个搜索失败不影响其他搜索
+		if len(status.Error) > 0 && string(status.Error) != "null" {
+			if itemErrors == nil {
+				itemErrors = make([]*errors.ESError, len(resp.Responses))
+			}
+			itemErrors[i] = errors.ParseESError(status.Status, raw)
+			continue
+		}
+
+		var result SearchResponse
+		if err := codec.Unmarshal(raw, &result); err != nil {
+			return nil, fmt.Errorf("解析第 %d 个搜索的响应失败: %w", i+1, err)
+		}
+		results[i] = &result
+	}
+
+	if itemErrors != nil {
+		return results, &MultiSearchError{Errors: itemErrors}
+	}
+	return results, nil
+}
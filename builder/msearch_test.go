@@ -0,0 +1,86 @@
+package builder
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+)
+
+func TestMultiSearchBuilder_Do(t *testing.T) {
+	client := createTestClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	indexName := "test_multi_search"
+	prepareSearchTestData(t, client, indexName)
+	defer func() {
+		_ = NewIndexBuilder(client, indexName).Delete(ctx)
+	}()
+
+	responses, err := NewMultiSearchBuilder(client).
+		Add(
+			NewSearchBuilder(client, indexName).Term("tags", "apple"),
+			NewSearchBuilder(client, indexName).Term("category", "electronics").Size(1),
+		).
+		AddWithHeader(NewSearchBuilder(client, indexName).Size(0), map[string]interface{}{"preference": "_local"}).
+		MaxConcurrentSearches(2).
+		Do(ctx)
+	if err != nil {
+		t.Fatalf("批量搜索失败: %v", err)
+	}
+	if len(responses) != 3 {
+		t.Fatalf("应该返回 3 个结果，实际 %d", len(responses))
+	}
+	if responses[0].Hits.Total.Value != 4 {
+		t.Errorf("第 1 个搜索应该找到 4 条结果，实际 %d", responses[0].Hits.Total.Value)
+	}
+	if responses[1].Hits.Total.Value != 2 || len(responses[1].Hits.Hits) != 1 {
+		t.Errorf("第 2 个搜索应该共 2 条、返回 1 条，实际共 %d 条、返回 %d 条",
+			responses[1].Hits.Total.Value, len(responses[1].Hits.Hits))
+	}
+	if responses[2].Hits.Total.Value != 5 {
+		t.Errorf("第 3 个搜索应该找到 5 条结果，实际 %d", responses[2].Hits.Total.Value)
+	}
+
+	t.Logf("✓ 批量搜索成功")
+}
+
+func TestMultiSearchBuilder_PartialFailure(t *testing.T) {
+	client := createTestClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	indexName := "test_multi_search_partial"
+	prepareSearchTestData(t, client, indexName)
+	defer func() {
+		_ = NewIndexBuilder(client, indexName).Delete(ctx)
+	}()
+
+	responses, err := NewMultiSearchBuilder(client).
+		Add(
+			NewSearchBuilder(client, indexName).Term("tags", "apple"),
+			NewSearchBuilder(client, "test_multi_search_missing"),
+		).
+		Do(ctx)
+
+	var msErr *MultiSearchError
+	if !stderrors.As(err, &msErr) {
+		t.Fatalf("应该返回 MultiSearchError，实际 %v", err)
+	}
+	if msErr.Errors[0] != nil {
+		t.Errorf("第 1 个搜索不应该失败: %v", msErr.Errors[0])
+	}
+	if msErr.Errors[1] == nil || !msErr.Errors[1].IsNotFound() {
+		t.Errorf("第 2 个搜索应该返回 404，实际 %v", msErr.Errors[1])
+	}
+
+	// 成功的搜索结果仍然可用
+	if responses[0] == nil || responses[0].Hits.Total.Value != 4 {
+		t.Errorf("第 1 个搜索应该找到 4 条结果")
+	}
+	if responses[1] != nil {
+		t.Errorf("失败的搜索结果应该为 nil")
+	}
+
+	t.Logf("✓ 部分失败处理成功: %v", err)
+}
@@ -1,10 +1,22 @@
 package builder
 
-import "encoding/json"
+import "github.com/Kirby980/go-es/config"
 
 // NestedObject 嵌套对象构建器（用于构建嵌套字段）
 type NestedObject struct {
-	data map[string]interface{}
+	data  map[string]interface{}
+	codec config.Codec
+}
+
+// newNestedObject 创建嵌套对象构建器
+func newNestedObject(codec config.Codec) *NestedObject {
+	return &NestedObject{data: make(map[string]interface{}), codec: codec}
+}
+
+// structToMap 通过编解码器将结构体转换为 map
+func structToMap(codec config.Codec, data interface{}, dst *map[string]interface{}) {
+	jsonData, _ := codec.Marshal(data)
+	codec.Unmarshal(jsonData, dst)
 }
 
 // Set 设置嵌套对象的字段
@@ -15,7 +27,7 @@ func (o *NestedObject) Set(key string, value interface{}) *NestedObject {
 
 // SetObject 设置嵌套对象的子对象
 func (o *NestedObject) SetObject(key string, builder func(*NestedObject)) *NestedObject {
-	nested := &NestedObject{data: make(map[string]interface{})}
+	nested := newNestedObject(o.codec)
 	builder(nested)
 	o.data[key] = nested.data
 	return o
@@ -31,7 +43,7 @@ func (o *NestedObject) SetArray(key string, values ...interface{}) *NestedObject
 func (o *NestedObject) SetObjectArray(key string, builders ...func(*NestedObject)) *NestedObject {
 	arr := make([]map[string]interface{}, len(builders))
 	for i, builder := range builders {
-		nested := &NestedObject{data: make(map[string]interface{})}
+		nested := newNestedObject(o.codec)
 		builder(nested)
 		arr[i] = nested.data
 	}
@@ -41,7 +53,6 @@ func (o *NestedObject) SetObjectArray(key string, builders ...func(*NestedObject
 
 // SetFromStruct 从结构体设置字段
 func (o *NestedObject) SetFromStruct(data interface{}) *NestedObject {
-	jsonData, _ := json.Marshal(data)
-	json.Unmarshal(jsonData, &o.data)
+	structToMap(o.codec, data, &o.data)
 	return o
 }
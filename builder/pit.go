@@ -0,0 +1,107 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/Kirby980/go-es/client"
+)
+
+// ========== Point in Time ==========
+//
+// PIT（point in time）固定索引在某一时刻的数据视图，分页期间的写入不会影响结果。
+// 使用 PIT 的搜索不指定索引，每次响应都会返回新的 PIT id，需要用新 id 继续查询。
+
+// PITResponse 打开 PIT 的响应
+type PITResponse struct {
+	ID string `json:"id"`
+}
+
+// OpenPIT 在索引上打开 PIT，keepAlive 如 "1m"，返回 PIT id
+// 多个构建器（如并发导出）可以通过 PIT 方法共享同一个 PIT
+func OpenPIT(ctx context.Context, c *client.Client, index, keepAlive string) (string, error) {
+	path := fmt.Sprintf("/%s/_pit?keep_alive=%s", index, url.QueryEscape(keepAlive))
+
+	respBody, err := c.Do(ctx, http.MethodPost, path, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var resp PITResponse
+	if err := c.Codec().Unmarshal(respBody, &resp); err != nil {
+		return "", fmt.Errorf("解析响应失败: %w", err)
+	}
+	return resp.ID, nil
+}
+
+// ClosePIT 关闭 PIT，释放占用的资源
+func ClosePIT(ctx context.Context, c *client.Client, id string) error {
+	body := map[string]interface{}{
+		"id": id,
+	}
+
+	_, err := c.Do(ctx, http.MethodDelete, "/_pit", body)
+	return err
+}
+
+// pointInTime 搜索使用的 PIT
+type pointInTime struct {
+	id        string
+	keepAlive string
+}
+
+// build 构建请求体中的 pit 参数，每次请求都通过 keep_alive 延长有效期
+func (p *pointInTime) build() map[string]interface{} {
+	pit := map[string]interface{}{
+		"id": p.id,
+	}
+	if p.keepAlive != "" {
+		pit["keep_alive"] = p.keepAlive
+	}
+	return pit
+}
+
+// jsonPIT 将 FromJSON 中的 pit 转换为 PIT
+func jsonPIT(value interface{}) (*pointInTime, error) {
+	obj, err := jsonObject("pit", value)
+	if err != nil {
+		return nil, err
+	}
+	id, ok := obj["id"].(string)
+	if !ok || id == "" {
+		return nil, fmt.Errorf("pit.id 必须是非空字符串")
+	}
+	keepAlive, _ := obj["keep_alive"].(string)
+	return &pointInTime{id: id, keepAlive: keepAlive}, nil
+}
+
+// update 使用响应中的新 PIT id
+func (p *pointInTime) update(id string) {
+	if p != nil && id != "" {
+		p.id = id
+	}
+}
+
+// searchPath 返回搜索路径，使用 PIT 时不能指定索引
+func searchPath(index string, pit *pointInTime) string {
+	if pit != nil {
+		return "/_search"
+	}
+	return fmt.Sprintf("/%s/_search", index)
+}
+
+// withShardDocTiebreaker 在排序末尾追加 _shard_doc，保证使用 PIT 时排序值唯一
+// 排序中已经包含 _shard_doc 时不重复追加
+func withShardDocTiebreaker(sort []map[string]interface{}) []map[string]interface{} {
+	for _, s := range sort {
+		if _, ok := s["_shard_doc"]; ok {
+			return sort
+		}
+	}
+
+	withTiebreaker := make([]map[string]interface{}, 0, len(sort)+1)
+	withTiebreaker = append(withTiebreaker, sort...)
+	return append(withTiebreaker, map[string]interface{}{"_shard_doc": "asc"})
+}
@@ -0,0 +1,41 @@
+package builder
+
+import (
+	"github.com/Kirby980/go-es/query"
+)
+
+// buildQuery 合并 Query 设置的查询与链式方法（Match、Term、Range 等）添加的 bool 条件
+// 只有一种时直接使用；两者都有时，Query 设置的查询作为 bool.must 的第一个条件；没有任何条件时返回 nil
+func buildQuery(root query.Query, must, filters, should, mustNot []map[string]interface{}, minimumShouldMatch interface{}) map[string]interface{} {
+	if len(must) == 0 && len(filters) == 0 && len(should) == 0 && len(mustNot) == 0 {
+		if root == nil {
+			return nil
+		}
+		return root.Build()
+	}
+
+	if root != nil {
+		must = append([]map[string]interface{}{root.Build()}, must...)
+	}
+
+	boolQuery := make(map[string]interface{})
+	if len(must) > 0 {
+		boolQuery["must"] = must
+	}
+	if len(filters) > 0 {
+		boolQuery["filter"] = filters
+	}
+	if len(should) > 0 {
+		boolQuery["should"] = should
+	}
+	if len(mustNot) > 0 {
+		boolQuery["must_not"] = mustNot
+	}
+	// 最少匹配 should 条件数量
+	if minimumShouldMatch != nil {
+		boolQuery["minimum_should_match"] = minimumShouldMatch
+	}
+	return map[string]interface{}{
+		"bool": boolQuery,
+	}
+}
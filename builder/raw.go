@@ -0,0 +1,131 @@
+package builder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/Kirby980/go-es/query"
+)
+
+// ========== 原始 DSL 与请求体合并 ==========
+//
+// RawQuery、Extra、FromJSON 用于链式 API 尚未覆盖的参数：
+//   - RawQuery 使用原始 JSON 设置查询，与 Match、Term 等链式条件的组合方式同 Query
+//   - Extra 设置请求体的顶层键（如 track_total_hits、timeout、collapse、rescore），
+//     与构建器生成的同名键冲突时以构建器为准
+//   - FromJSON 加载保存的请求体：query 以及构建器支持的参数（如 size）写入构建器，其余键作为 Extra
+//
+// JSON 解析失败不会中断链式调用，错误在 Do 等执行方法中返回
+
+// decodeJSONObject 解析 JSON 对象，数字保留为 json.Number，避免大整数丢失精度
+func decodeJSONObject(data []byte) (map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var obj map[string]interface{}
+	if err := dec.Decode(&obj); err != nil {
+		return nil, fmt.Errorf("解析 JSON 失败: %w", err)
+	}
+	if obj == nil {
+		return nil, fmt.Errorf("解析 JSON 失败: 必须是 JSON 对象")
+	}
+	return obj, nil
+}
+
+// rawQuery 解析 RawQuery 的查询
+func rawQuery(raw json.RawMessage) (map[string]interface{}, error) {
+	q, err := decodeJSONObject(raw)
+	if err != nil {
+		return nil, fmt.Errorf("解析查询失败: %w", err)
+	}
+	return q, nil
+}
+
+// jsonQuery 将 FromJSON 中的 query 转换为查询节点
+func jsonQuery(value interface{}) (query.Query, error) {
+	q, err := jsonObject("query", value)
+	if err != nil {
+		return nil, err
+	}
+	return query.Raw(q), nil
+}
+
+// jsonObject 将 FromJSON 中的值转换为 JSON 对象
+func jsonObject(key string, value interface{}) (map[string]interface{}, error) {
+	obj, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s 必须是 JSON 对象，实际为 %T", key, value)
+	}
+	return obj, nil
+}
+
+// jsonInt 将 FromJSON 中的值转换为整数
+func jsonInt(key string, value interface{}) (int, error) {
+	n, ok := value.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("%s 必须是整数，实际为 %T", key, value)
+	}
+	i, err := n.Int64()
+	if err != nil {
+		return 0, fmt.Errorf("%s 必须是整数: %w", key, err)
+	}
+	return int(i), nil
+}
+
+// jsonSort 将 FromJSON 中的 sort 转换为排序列表
+// 字符串形式的排序字段展开为对象：_score 默认降序，其他字段默认升序
+func jsonSort(value interface{}) ([]map[string]interface{}, error) {
+	items, ok := value.([]interface{})
+	if !ok {
+		items = []interface{}{value}
+	}
+
+	sort := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		switch v := item.(type) {
+		case string:
+			order := "asc"
+			if v == "_score" {
+				order = "desc"
+			}
+			sort = append(sort, map[string]interface{}{v: order})
+		case map[string]interface{}:
+			sort = append(sort, v)
+		default:
+			return nil, fmt.Errorf("sort 必须是字段名或对象，实际为 %T", item)
+		}
+	}
+	return sort, nil
+}
+
+// mergeAggs 将 FromJSON 中的 aggs（或 aggregations）合并到构建器的聚合中
+func mergeAggs(aggs map[string]interface{}, key string, value interface{}) error {
+	obj, err := jsonObject(key, value)
+	if err != nil {
+		return err
+	}
+	for name, agg := range obj {
+		aggs[name] = agg
+	}
+	return nil
+}
+
+// setExtra 设置请求体的顶层键，按需创建 map
+func setExtra(extra map[string]interface{}, key string, value interface{}) map[string]interface{} {
+	if extra == nil {
+		extra = make(map[string]interface{})
+	}
+	extra[key] = value
+	return extra
+}
+
+// mergeExtra 将 Extra 设置的键写入请求体，构建器已生成的键不会被覆盖
+func mergeExtra(body, extra map[string]interface{}) map[string]interface{} {
+	for k, v := range extra {
+		if _, ok := body[k]; !ok {
+			body[k] = v
+		}
+	}
+	return body
+}
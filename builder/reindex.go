@@ -0,0 +1,346 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/Kirby980/go-es/client"
+	"github.com/Kirby980/go-es/query"
+)
+
+// ReindexBuilder 重建索引构建器（_reindex）
+type ReindexBuilder struct {
+	client       *client.Client
+	source       []string
+	sourceFields []string
+	batchSize    int
+	remote       map[string]interface{}
+	dest         map[string]interface{}
+	filters      []map[string]interface{}
+	must         []map[string]interface{}
+	mustNot      []map[string]interface{}
+	rootQuery    query.Query // Query 设置的查询
+	script       map[string]interface{}
+	maxDocs      int
+	conflicts    string
+	params       url.Values
+	debug        bool
+}
+
+// NewReindexBuilder 创建重建索引构建器
+func NewReindexBuilder(c *client.Client) *ReindexBuilder {
+	return &ReindexBuilder{
+		client:  c,
+		dest:    make(map[string]interface{}),
+		filters: make([]map[string]interface{}, 0),
+		must:    make([]map[string]interface{}, 0),
+		mustNot: make([]map[string]interface{}, 0),
+		params:  url.Values{},
+	}
+}
+
+// ========== 源索引 ==========
+
+// Source 设置源索引
+func (b *ReindexBuilder) Source(indices ...string) *ReindexBuilder {
+	b.source = append(b.source, indices...)
+	return b
+}
+
+// SourceFields 只复制指定字段
+func (b *ReindexBuilder) SourceFields(fields ...string) *ReindexBuilder {
+	b.sourceFields = fields
+	return b
+}
+
+// BatchSize 设置每批读取的文档数（source.size，默认 1000）
+func (b *ReindexBuilder) BatchSize(size int) *ReindexBuilder {
+	b.batchSize = size
+	return b
+}
+
+// Remote 从远程集群重建（远程地址需要在目标集群的 reindex.remote.whitelist 中）
+// username 为空时不使用认证
+func (b *ReindexBuilder) Remote(host, username, password string) *ReindexBuilder {
+	b.remote = map[string]interface{}{
+		"host": host,
+	}
+	if username != "" {
+		b.remote["username"] = username
+		b.remote["password"] = password
+	}
+	return b
+}
+
+// ========== 查询条件 ==========
+
+// Query 设置查询（query 包构建的查询节点），只重建匹配的文档
+// 与 Match、Term 等链式方法添加的条件同时使用时，以 bool.must 组合
+func (b *ReindexBuilder) Query(q query.Query) *ReindexBuilder {
+	b.rootQuery = q
+	return b
+}
+
+// Match 添加 match 查询条件（只重建匹配的文档）
+func (b *ReindexBuilder) Match(field string, value interface{}) *ReindexBuilder {
+	b.must = append(b.must, map[string]interface{}{
+		"match": map[string]interface{}{
+			field: value,
+		},
+	})
+	return b
+}
+
+// Term 添加 term 查询条件
+func (b *ReindexBuilder) Term(field string, value interface{}) *ReindexBuilder {
+	b.filters = append(b.filters, map[string]interface{}{
+		"term": map[string]interface{}{
+			field: value,
+		},
+	})
+	return b
+}
+
+// Terms 添加 terms 查询条件
+func (b *ReindexBuilder) Terms(field string, values ...interface{}) *ReindexBuilder {
+	b.filters = append(b.filters, map[string]interface{}{
+		"terms": map[string]interface{}{
+			field: values,
+		},
+	})
+	return b
+}
+
+// Range 添加范围查询条件
+func (b *ReindexBuilder) Range(field string, gte, lte interface{}) *ReindexBuilder {
+	rangeQuery := make(map[string]interface{})
+	if gte != nil {
+		rangeQuery["gte"] = gte
+	}
+	if lte != nil {
+		rangeQuery["lte"] = lte
+	}
+	b.filters = append(b.filters, map[string]interface{}{
+		"range": map[string]interface{}{
+			field: rangeQuery,
+		},
+	})
+	return b
+}
+
+// Exists 添加字段存在查询
+func (b *ReindexBuilder) Exists(field string) *ReindexBuilder {
+	b.filters = append(b.filters, map[string]interface{}{
+		"exists": map[string]interface{}{
+			"field": field,
+		},
+	})
+	return b
+}
+
+// MustNot 添加 must_not 条件（term 查询）
+func (b *ReindexBuilder) MustNot(field string, value interface{}) *ReindexBuilder {
+	b.mustNot = append(b.mustNot, map[string]interface{}{
+		"term": map[string]interface{}{
+			field: value,
+		},
+	})
+	return b
+}
+
+// ========== 目标索引 ==========
+
+// Dest 设置目标索引
+func (b *ReindexBuilder) Dest(index string) *ReindexBuilder {
+	b.dest["index"] = index
+	return b
+}
+
+// Pipeline 设置目标索引使用的 ingest pipeline
+func (b *ReindexBuilder) Pipeline(pipeline string) *ReindexBuilder {
+	b.dest["pipeline"] = pipeline
+	return b
+}
+
+// OpType 设置写入方式，"create" 时只写入目标索引中不存在的文档
+func (b *ReindexBuilder) OpType(opType string) *ReindexBuilder {
+	b.dest["op_type"] = opType
+	return b
+}
+
+// ========== 执行控制 ==========
+
+// Script 设置重建时对文档执行的脚本
+func (b *ReindexBuilder) Script(source string, params map[string]interface{}) *ReindexBuilder {
+	b.script = map[string]interface{}{
+		"source": source,
+		"lang":   "painless",
+	}
+	if params != nil {
+		b.script["params"] = params
+	}
+	return b
+}
+
+// MaxDocs 最多重建的文档数
+func (b *ReindexBuilder) MaxDocs(maxDocs int) *ReindexBuilder {
+	b.maxDocs = maxDocs
+	return b
+}
+
+// ConflictsProceed 遇到版本冲突时继续执行（默认遇到冲突中止）
+func (b *ReindexBuilder) ConflictsProceed() *ReindexBuilder {
+	b.conflicts = "proceed"
+	return b
+}
+
+// Slices 设置切片数，并行执行重建
+func (b *ReindexBuilder) Slices(slices int) *ReindexBuilder {
+	b.params.Set("slices", strconv.Itoa(slices))
+	return b
+}
+
+// SlicesAuto 由 ES 根据分片数自动选择切片数
+func (b *ReindexBuilder) SlicesAuto() *ReindexBuilder {
+	b.params.Set("slices", "auto")
+	return b
+}
+
+// RequestsPerSecond 限制每秒处理的文档数，-1 表示不限制
+func (b *ReindexBuilder) RequestsPerSecond(rps float64) *ReindexBuilder {
+	b.params.Set("requests_per_second", strconv.FormatFloat(rps, 'f', -1, 64))
+	return b
+}
+
+// Refresh 完成后刷新目标索引
+func (b *ReindexBuilder) Refresh() *ReindexBuilder {
+	b.params.Set("refresh", "true")
+	return b
+}
+
+// Debug 启用调试模式
+func (b *ReindexBuilder) Debug() *ReindexBuilder {
+	b.debug = true
+	return b
+}
+
+// resetDebug 执行后重置debug标志（让每次调用可以独立控制）
+func (b *ReindexBuilder) resetDebug() {
+	b.debug = false
+}
+
+// Build 构建请求体
+func (b *ReindexBuilder) Build() map[string]interface{} {
+	source := map[string]interface{}{
+		"index": b.source,
+	}
+
+	if q := buildQuery(b.rootQuery, b.must, b.filters, nil, b.mustNot, nil); q != nil {
+		source["query"] = q
+	}
+	if len(b.sourceFields) > 0 {
+		source["_source"] = b.sourceFields
+	}
+	if b.batchSize > 0 {
+		source["size"] = b.batchSize
+	}
+	if b.remote != nil {
+		source["remote"] = b.remote
+	}
+
+	body := map[string]interface{}{
+		"source": source,
+		"dest":   b.dest,
+	}
+	if b.script != nil {
+		body["script"] = b.script
+	}
+	if b.maxDocs > 0 {
+		body["max_docs"] = b.maxDocs
+	}
+	if b.conflicts != "" {
+		body["conflicts"] = b.conflicts
+	}
+
+	return body
+}
+
+// ReindexResponse 重建索引响应
+type ReindexResponse struct {
+	Took             int   `json:"took"`
+	TimedOut         bool  `json:"timed_out"`
+	Total            int64 `json:"total"`
+	Created          int64 `json:"created"`
+	Updated          int64 `json:"updated"`
+	Deleted          int64 `json:"deleted"`
+	Batches          int   `json:"batches"`
+	VersionConflicts int64 `json:"version_conflicts"`
+	Noops            int64 `json:"noops"`
+	Retries          struct {
+		Bulk   int `json:"bulk"`
+		Search int `json:"search"`
+	} `json:"retries"`
+	ThrottledMillis      int                      `json:"throttled_millis"`
+	RequestsPerSecond    float64                  `json:"requests_per_second"`
+	ThrottledUntilMillis int                      `json:"throttled_until_millis"`
+	Failures             []map[string]interface{} `json:"failures"`
+}
+
+// validate 检查必填参数
+func (b *ReindexBuilder) validate() error {
+	if len(b.source) == 0 {
+		return fmt.Errorf("必须设置源索引")
+	}
+	if b.dest["index"] == nil {
+		return fmt.Errorf("必须设置目标索引")
+	}
+	return nil
+}
+
+// Do 同步执行重建索引，数据量较大时建议使用 Start 避免请求超时
+func (b *ReindexBuilder) Do(ctx context.Context) (*ReindexResponse, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+
+	// 如果启用调试模式，由客户端打印请求和响应信息
+	if b.debug {
+		ctx = client.WithDebug(ctx)
+		defer b.resetDebug()
+	}
+
+	respBody, err := b.client.Do(ctx, http.MethodPost, requestPath("/_reindex", b.params), b.Build())
+	if err != nil {
+		return nil, err
+	}
+
+	var resp ReindexResponse
+	if err := b.client.Codec().Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+
+	return &resp, nil
+}
+
+// Start 异步执行重建索引，返回任务句柄
+// 通过 TaskHandle.Wait 等待完成，再用 TaskStatus.DecodeResponse 解析为 ReindexResponse
+func (b *ReindexBuilder) Start(ctx context.Context) (*TaskHandle, error) {
+	if err := b.validate(); err != nil {
+		return nil, err
+	}
+
+	// 如果启用调试模式，由客户端打印请求和响应信息
+	if b.debug {
+		ctx = client.WithDebug(ctx)
+		defer b.resetDebug()
+	}
+
+	// 复制查询参数，避免 Start 修改构建器状态
+	params := maps.Clone(b.params)
+	params.Set("wait_for_completion", "false")
+	return startTask(ctx, b.client, http.MethodPost, requestPath("/_reindex", params), b.Build())
+}
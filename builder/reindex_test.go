@@ -0,0 +1,125 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Kirby980/go-es/client"
+)
+
+// prepareReindexSource 创建源索引并写入测试数据
+func prepareReindexSource(t *testing.T, esClient *client.Client, source string) {
+	ctx := context.Background()
+	prepareTestIndex(t, esClient, source)
+
+	bulk := NewBulkBuilder(esClient)
+	for i := 1; i <= 10; i++ {
+		bulk.Add(source, fmt.Sprintf("%d", i), map[string]interface{}{
+			"title":     fmt.Sprintf("文章 %d", i),
+			"author":    []string{"alice", "bob"}[i%2],
+			"views":     i * 100,
+			"published": i%2 == 0,
+		})
+	}
+	if _, err := bulk.Do(ctx); err != nil {
+		t.Fatalf("写入数据失败: %v", err)
+	}
+	time.Sleep(2 * time.Second) // 等待索引刷新
+}
+
+// TestReindexBuilder_Do 测试同步重建索引
+func TestReindexBuilder_Do(t *testing.T) {
+	client := createTestClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	source, dest := "test_reindex_source", "test_reindex_dest"
+	prepareReindexSource(t, client, source)
+	_ = NewIndexBuilder(client, dest).Delete(ctx)
+	defer func() {
+		_ = NewIndexBuilder(client, source).Delete(ctx)
+		_ = NewIndexBuilder(client, dest).Delete(ctx)
+	}()
+
+	resp, err := NewReindexBuilder(client).
+		Source(source).
+		Term("published", true).
+		Dest(dest).
+		Script("ctx._source.views = ctx._source.views * 2", nil).
+		Refresh().
+		Do(ctx)
+	if err != nil {
+		t.Fatalf("重建索引失败: %v", err)
+	}
+	if resp.Created != 5 {
+		t.Errorf("应该重建 5 个文档，实际 %d", resp.Created)
+	}
+
+	// OpType("create") 只写入不存在的文档，已存在的文档计为版本冲突
+	resp, err = NewReindexBuilder(client).
+		Source(source).
+		Dest(dest).
+		OpType("create").
+		ConflictsProceed().
+		Refresh().
+		Do(ctx)
+	if err != nil {
+		t.Fatalf("重建索引失败: %v", err)
+	}
+	if resp.Created != 5 || resp.VersionConflicts != 5 {
+		t.Errorf("应该新建 5 个文档、冲突 5 个，实际新建 %d、冲突 %d", resp.Created, resp.VersionConflicts)
+	}
+	t.Logf("✓ 重建索引成功: %+v", resp)
+}
+
+// TestReindexBuilder_Start 测试异步重建索引与任务跟踪
+func TestReindexBuilder_Start(t *testing.T) {
+	client := createTestClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	source, dest := "test_reindex_async_source", "test_reindex_async_dest"
+	prepareReindexSource(t, client, source)
+	_ = NewIndexBuilder(client, dest).Delete(ctx)
+	defer func() {
+		_ = NewIndexBuilder(client, source).Delete(ctx)
+		_ = NewIndexBuilder(client, dest).Delete(ctx)
+	}()
+
+	task, err := NewReindexBuilder(client).
+		Source(source).
+		Dest(dest).
+		BatchSize(2).
+		MaxDocs(6).
+		RequestsPerSecond(-1).
+		Start(ctx)
+	if err != nil {
+		t.Fatalf("启动重建任务失败: %v", err)
+	}
+	t.Logf("任务 ID: %s", task.ID)
+
+	status, err := task.Wait(ctx, 200*time.Millisecond)
+	if err != nil {
+		t.Fatalf("等待任务失败: %v", err)
+	}
+
+	var resp ReindexResponse
+	if err := status.DecodeResponse(client, &resp); err != nil {
+		t.Fatalf("解析任务响应失败: %v", err)
+	}
+	if resp.Created != 6 {
+		t.Errorf("应该重建 6 个文档，实际 %d", resp.Created)
+	}
+
+	// 通过 ClusterBuilder 查询同一任务
+	clusterStatus, err := NewClusterBuilder(client).Task(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("查询任务失败: %v", err)
+	}
+	if !clusterStatus.Completed {
+		t.Error("任务应该已完成")
+	}
+	t.Logf("✓ 异步重建索引成功: %+v", clusterStatus.Task.Status)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	"github.com/Kirby980/go-es/client"
+	"github.com/Kirby980/go-es/query"
 )
 
 // ScrollBuilder Scroll深度分页构建器
@@ -17,9 +18,15 @@ type ScrollBuilder struct {
 	must      []map[string]interface{}
 	should    []map[string]interface{}
 	mustNot   []map[string]interface{}
+	rootQuery query.Query // Query 设置的查询
 	size      int
 	keepAlive string
 	scrollID  string
+	slices    int                    // 并发遍历的切片数量
+	workers   int                    // 并发遍历的最大 goroutine 数量
+	usePIT    bool                   // 并发遍历使用 PIT 而不是 scroll
+	extra     map[string]interface{} // Extra 设置的顶层键
+	err       error                  // RawQuery、FromJSON 的解析错误
 	debug     bool
 }
 
@@ -37,6 +44,58 @@ func NewScrollBuilder(c *client.Client, index string) *ScrollBuilder {
 	}
 }
 
+// Query 设置查询（query 包构建的查询节点）
+// 与 Match、Term 等链式方法添加的条件同时使用时，以 bool.must 组合
+func (b *ScrollBuilder) Query(q query.Query) *ScrollBuilder {
+	b.rootQuery = q
+	return b
+}
+
+// RawQuery 使用原始 JSON 设置查询
+// 与 Match、Term 等链式方法添加的条件同时使用时，以 bool.must 组合
+func (b *ScrollBuilder) RawQuery(raw json.RawMessage) *ScrollBuilder {
+	q, err := rawQuery(raw)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	b.rootQuery = query.Raw(q)
+	return b
+}
+
+// Extra 设置第一次 scroll 请求体的顶层键，如 sort、_source、slice
+// 与构建器生成的同名键（query、size）冲突时以构建器为准
+func (b *ScrollBuilder) Extra(key string, value interface{}) *ScrollBuilder {
+	b.extra = setExtra(b.extra, key, value)
+	return b
+}
+
+// FromJSON 加载保存的搜索请求体
+// query、size 写入构建器，其余键等同于 Extra
+func (b *ScrollBuilder) FromJSON(data []byte) *ScrollBuilder {
+	body, err := decodeJSONObject(data)
+	if err != nil {
+		b.err = err
+		return b
+	}
+
+	for key, value := range body {
+		switch key {
+		case "query":
+			b.rootQuery, err = jsonQuery(value)
+		case "size":
+			b.size, err = jsonInt(key, value)
+		default:
+			b.extra = setExtra(b.extra, key, value)
+		}
+		if err != nil {
+			b.err = fmt.Errorf("加载请求体失败: %w", err)
+			return b
+		}
+	}
+	return b
+}
+
 // Match 添加 match 查询条件
 func (b *ScrollBuilder) Match(field string, value interface{}) *ScrollBuilder {
 	b.must = append(b.must, map[string]interface{}{
@@ -86,27 +145,30 @@ func (b *ScrollBuilder) KeepAlive(keepAlive string) *ScrollBuilder {
 	return b
 }
 
-// Debug 启用调试模式
-func (b *ScrollBuilder) Debug() *ScrollBuilder {
-	b.debug = true
+// Slices 设置 Each、Stream 并发遍历的切片数量，每个切片使用独立的 scroll（或 PIT 查询）
+// 切片数量不建议超过索引的分片数
+func (b *ScrollBuilder) Slices(n int) *ScrollBuilder {
+	b.slices = n
 	return b
 }
 
-// printDebug 打印请求调试信息
-func (b *ScrollBuilder) printDebug(method, path string, body interface{}) {
-	fmt.Printf("\n[ES Debug] %s %s\n", method, path)
-	if body != nil {
-		data, _ := json.MarshalIndent(body, "", "  ")
-		fmt.Printf("Request Body:\n%s\n", string(data))
-	}
+// Concurrency 设置 Each、Stream 同时消费的最大切片数量（默认等于切片数量）
+func (b *ScrollBuilder) Concurrency(n int) *ScrollBuilder {
+	b.workers = n
+	return b
 }
 
-// printResponse 打印响应调试信息
-func (b *ScrollBuilder) printResponse(respBody []byte) {
-	var pretty interface{}
-	json.Unmarshal(respBody, &pretty)
-	data, _ := json.MarshalIndent(pretty, "", "  ")
-	fmt.Printf("Response:\n%s\n\n", string(data))
+// UsePIT Each、Stream 使用 PIT + search_after 遍历切片，而不是 scroll
+// PIT 不占用 scroll 上下文，适合长时间导出
+func (b *ScrollBuilder) UsePIT() *ScrollBuilder {
+	b.usePIT = true
+	return b
+}
+
+// Debug 启用调试模式
+func (b *ScrollBuilder) Debug() *ScrollBuilder {
+	b.debug = true
+	return b
 }
 
 // resetDebug 执行后重置debug标志（让每次调用可以独立控制）
@@ -119,28 +181,13 @@ func (b *ScrollBuilder) Build() map[string]interface{} {
 	body := make(map[string]interface{})
 
 	// 构建查询条件
-	if len(b.must) > 0 || len(b.filters) > 0 || len(b.should) > 0 || len(b.mustNot) > 0 {
-		boolQuery := make(map[string]interface{})
-		if len(b.must) > 0 {
-			boolQuery["must"] = b.must
-		}
-		if len(b.filters) > 0 {
-			boolQuery["filter"] = b.filters
-		}
-		if len(b.should) > 0 {
-			boolQuery["should"] = b.should
-		}
-		if len(b.mustNot) > 0 {
-			boolQuery["must_not"] = b.mustNot
-		}
-		body["query"] = map[string]interface{}{
-			"bool": boolQuery,
-		}
+	if q := buildQuery(b.rootQuery, b.must, b.filters, b.should, b.mustNot, nil); q != nil {
+		body["query"] = q
 	}
 
 	body["size"] = b.size
 
-	return body
+	return mergeExtra(body, b.extra)
 }
 
 // ScrollResponse Scroll响应
@@ -170,30 +217,44 @@ type ScrollResponse struct {
 	} `json:"hits"`
 }
 
-// Do 执行第一次scroll查询
-func (b *ScrollBuilder) Do(ctx context.Context) (*ScrollResponse, error) {
-	path := fmt.Sprintf("/%s/_search?scroll=%s", b.index, b.keepAlive)
-	body := b.Build()
+// firstRequest 返回第一次 scroll 查询的路径和请求体
+func (b *ScrollBuilder) firstRequest() (string, map[string]interface{}, error) {
+	if b.err != nil {
+		return "", nil, b.err
+	}
+	return fmt.Sprintf("/%s/_search?scroll=%s", b.index, b.keepAlive), b.Build(), nil
+}
 
-	// 如果启用调试模式，打印请求信息
-	if b.debug {
-		b.printDebug("POST", path, body)
-		defer b.resetDebug()
+// nextRequest 返回获取下一批数据的路径和请求体
+func (b *ScrollBuilder) nextRequest() (string, map[string]interface{}, error) {
+	if b.scrollID == "" {
+		return "", nil, fmt.Errorf("请先调用Do()方法初始化scroll")
+	}
+
+	body := map[string]interface{}{
+		"scroll":    b.keepAlive,
+		"scroll_id": b.scrollID,
 	}
+	return "/_search/scroll", body, nil
+}
 
-	respBody, err := b.client.Do(ctx, http.MethodPost, path, body)
+// Do 执行第一次scroll查询
+func (b *ScrollBuilder) Do(ctx context.Context) (*ScrollResponse, error) {
+	path, body, err := b.firstRequest()
 	if err != nil {
 		return nil, err
 	}
 
-	// 如果启用调试模式，打印响应信息
+	// 如果启用调试模式，由客户端打印请求和响应信息
 	if b.debug {
-		b.printResponse(respBody)
+		ctx = client.WithDebug(ctx)
+		defer b.resetDebug()
 	}
 
+	// 流式解析响应，命中逐条解码
 	var resp ScrollResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return nil, fmt.Errorf("解析响应失败: %w", err)
+	if err := streamSearch(ctx, b.client, http.MethodPost, path, body, &resp, appendHit(&resp.Hits.Hits)); err != nil {
+		return nil, err
 	}
 
 	// 保存scroll ID供下次使用
@@ -204,35 +265,21 @@ func (b *ScrollBuilder) Do(ctx context.Context) (*ScrollResponse, error) {
 
 // Next 获取下一批数据
 func (b *ScrollBuilder) Next(ctx context.Context) (*ScrollResponse, error) {
-	if b.scrollID == "" {
-		return nil, fmt.Errorf("请先调用Do()方法初始化scroll")
-	}
-
-	path := "/_search/scroll"
-	body := map[string]interface{}{
-		"scroll":    b.keepAlive,
-		"scroll_id": b.scrollID,
-	}
-
-	// 如果启用调试模式，打印请求信息
-	if b.debug {
-		b.printDebug("POST", path, body)
-		defer b.resetDebug()
-	}
-
-	respBody, err := b.client.Do(ctx, http.MethodPost, path, body)
+	path, body, err := b.nextRequest()
 	if err != nil {
 		return nil, err
 	}
 
-	// 如果启用调试模式，打印响应信息
+	// 如果启用调试模式，由客户端打印请求和响应信息
 	if b.debug {
-		b.printResponse(respBody)
+		ctx = client.WithDebug(ctx)
+		defer b.resetDebug()
 	}
 
+	// 流式解析响应，命中逐条解码
 	var resp ScrollResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return nil, fmt.Errorf("解析响应失败: %w", err)
+	if err := streamSearch(ctx, b.client, http.MethodPost, path, body, &resp, appendHit(&resp.Hits.Hits)); err != nil {
+		return nil, err
 	}
 
 	// 更新scroll ID
@@ -247,31 +294,29 @@ func (b *ScrollBuilder) Clear(ctx context.Context) error {
 		return nil
 	}
 
-	path := "/_search/scroll"
-	body := map[string]interface{}{
-		"scroll_id": b.scrollID,
-	}
-
-	// 如果启用调试模式，打印请求信息
+	// 如果启用调试模式，由客户端打印请求和响应信息
 	if b.debug {
-		b.printDebug("DELETE", path, body)
+		ctx = client.WithDebug(ctx)
 		defer b.resetDebug()
 	}
 
-	respBody, err := b.client.Do(ctx, http.MethodDelete, path, body)
-	if err != nil {
+	if err := clearScroll(ctx, b.client, b.scrollID); err != nil {
 		return err
 	}
 
-	// 如果启用调试模式，打印响应信息
-	if b.debug {
-		b.printResponse(respBody)
-	}
-
 	b.scrollID = ""
 	return nil
 }
 
+// clearScroll 清除 scroll 上下文
+func clearScroll(ctx context.Context, c *client.Client, scrollID string) error {
+	body := map[string]interface{}{
+		"scroll_id": scrollID,
+	}
+	_, err := c.Do(ctx, http.MethodDelete, "/_search/scroll", body)
+	return err
+}
+
 // HasMore 判断是否还有更多数据
 func (b *ScrollBuilder) HasMore(resp *ScrollResponse) bool {
 	return len(resp.Hits.Hits) > 0
@@ -0,0 +1,262 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Kirby980/go-es/client"
+)
+
+// ========== 切片并发遍历 ==========
+
+// cleanupTimeout 清除 scroll 上下文、关闭 PIT 的超时时间
+const cleanupTimeout = 10 * time.Second
+
+// cleanupContext 返回清理资源使用的 context
+// 不随调用方取消（ctx 已经结束时仍然需要清理），但有独立的超时，避免节点无响应时一直阻塞
+func cleanupContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+}
+
+// Each 遍历所有匹配的文档，每个命中调用一次 fn
+// 设置 Slices 时并发消费各个切片，fn 会被多个 goroutine 同时调用，需要自行保证并发安全
+// fn 返回错误或 ctx 结束时停止遍历，返回前清除所有 scroll 上下文（使用 PIT 时关闭 PIT）
+func (b *ScrollBuilder) Each(ctx context.Context, fn func(hit Hit) error) error {
+	return EachAs(ctx, b, fn)
+}
+
+// Stream 遍历所有匹配的文档，命中依次发送到 out，结束后关闭 out
+// 接收方提前停止接收时需要取消 ctx，否则 Stream 会一直阻塞
+func (b *ScrollBuilder) Stream(ctx context.Context, out chan<- Hit) error {
+	defer close(out)
+
+	return b.Each(ctx, func(hit Hit) error {
+		select {
+		case out <- hit:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
+
+// EachAs 遍历所有匹配的文档，命中的 _source 解码为 T，用法同 ScrollBuilder.Each
+func EachAs[T any](ctx context.Context, b *ScrollBuilder, fn func(hit TypedHit[T]) error) (err error) {
+	if b.err != nil {
+		return b.err
+	}
+
+	// 如果启用调试模式，由客户端打印请求和响应信息
+	if b.debug {
+		ctx = client.WithDebug(ctx)
+		defer b.resetDebug()
+	}
+
+	slices := max(b.slices, 1)
+	workers := slices
+	if b.workers > 0 && b.workers < workers {
+		workers = b.workers
+	}
+
+	// 所有切片共享同一个 PIT
+	var pit *sharedPIT
+	if b.usePIT {
+		id, err := OpenPIT(ctx, b.client, b.index, b.keepAlive)
+		if err != nil {
+			return fmt.Errorf("打开 PIT 失败: %w", err)
+		}
+		pit = &sharedPIT{pit: pointInTime{id: id, keepAlive: b.keepAlive}}
+		defer func() {
+			closeCtx, cancel := cleanupContext(ctx)
+			defer cancel()
+			// 关闭 ES 最新返回的 PIT id
+			if closeErr := ClosePIT(closeCtx, b.client, pit.id()); closeErr != nil && err == nil {
+				err = fmt.Errorf("关闭 PIT 失败: %w", closeErr)
+			}
+		}()
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// 任意切片失败时取消其他切片
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	jobs := make(chan int)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range jobs {
+				var err error
+				if pit != nil {
+					err = eachPITSlice(runCtx, b, pit, id, slices, fn)
+				} else {
+					err = eachScrollSlice(runCtx, b, id, slices, fn)
+				}
+				if err != nil {
+					if slices > 1 {
+						err = fmt.Errorf("切片 %d: %w", id, err)
+					}
+					fail(err)
+				}
+			}
+		}()
+	}
+
+send:
+	for id := 0; id < slices; id++ {
+		select {
+		case jobs <- id:
+		case <-runCtx.Done():
+			break send
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// sliceParam 构建 slice 参数，只有一个切片时不需要
+func sliceParam(id, total int) map[string]interface{} {
+	if total <= 1 {
+		return nil
+	}
+	return map[string]interface{}{
+		"id":  id,
+		"max": total,
+	}
+}
+
+// eachScrollSlice 使用 scroll 遍历一个切片，退出时清除 scroll 上下文
+func eachScrollSlice[T any](ctx context.Context, b *ScrollBuilder, id, total int, fn func(hit TypedHit[T]) error) (err error) {
+	path := fmt.Sprintf("/%s/_search?scroll=%s", b.index, b.keepAlive)
+	body := b.Build()
+	if slice := sliceParam(id, total); slice != nil {
+		body["slice"] = slice
+	}
+
+	var scrollID string
+	defer func() {
+		if scrollID == "" {
+			return
+		}
+		clearCtx, cancel := cleanupContext(ctx)
+		defer cancel()
+		if clearErr := clearScroll(clearCtx, b.client, scrollID); clearErr != nil && err == nil {
+			err = fmt.Errorf("清除 scroll 失败: %w", clearErr)
+		}
+	}()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		resp, searchErr := typedSearch[T](ctx, b.client, path, body)
+		if searchErr != nil {
+			return searchErr
+		}
+		if resp.ScrollID != "" {
+			scrollID = resp.ScrollID
+		}
+		if len(resp.Hits.Hits) == 0 {
+			return nil
+		}
+
+		if err := eachHit(ctx, resp.Hits.Hits, fn); err != nil {
+			return err
+		}
+
+		path = "/_search/scroll"
+		body = map[string]interface{}{
+			"scroll":    b.keepAlive,
+			"scroll_id": scrollID,
+		}
+	}
+}
+
+// sharedPIT 各个切片共享的 PIT，ES 返回新的 PIT id 时所有切片都使用新 id
+type sharedPIT struct {
+	mu  sync.Mutex
+	pit pointInTime
+}
+
+// build 构建请求体中的 pit 参数
+func (p *sharedPIT) build() map[string]interface{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pit.build()
+}
+
+// update 使用响应中的新 PIT id
+func (p *sharedPIT) update(id string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.pit.update(id)
+}
+
+// id 返回最新的 PIT id
+func (p *sharedPIT) id() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pit.id
+}
+
+// eachPITSlice 使用 PIT + search_after 遍历一个切片
+func eachPITSlice[T any](ctx context.Context, b *ScrollBuilder, pit *sharedPIT, id, total int, fn func(hit TypedHit[T]) error) error {
+	body := b.Build()
+	body["sort"] = withShardDocTiebreaker(nil)
+	if slice := sliceParam(id, total); slice != nil {
+		body["slice"] = slice
+	}
+
+	for {
+		body["pit"] = pit.build()
+		resp, err := typedSearch[T](ctx, b.client, "/_search", body)
+		if err != nil {
+			return err
+		}
+		pit.update(resp.PitID)
+
+		n := len(resp.Hits.Hits)
+		if n == 0 {
+			return nil
+		}
+
+		if err := eachHit(ctx, resp.Hits.Hits, fn); err != nil {
+			return err
+		}
+
+		body["search_after"] = resp.Hits.Hits[n-1].Sort
+	}
+}
+
+// eachHit 依次处理一页命中，其他切片失败或 ctx 结束时立即停止
+func eachHit[T any](ctx context.Context, hits []TypedHit[T], fn func(hit TypedHit[T]) error) error {
+	for _, hit := range hits {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(hit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
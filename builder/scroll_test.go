@@ -2,6 +2,15 @@ package builder
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -541,3 +550,266 @@ func TestScrollBuilder_LargeDataset(t *testing.T) {
 	// 清理
 	scroll.Clear(ctx)
 }
+
+// TestScrollBuilder_Slices 测试切片并发遍历
+func TestScrollBuilder_Slices(t *testing.T) {
+	client := createScrollTestClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	indexName := "test_scroll_slices"
+	defer NewIndexBuilder(client, indexName).Delete(ctx)
+
+	prepareScrollTestData(t, client, indexName, 300)
+
+	for _, usePIT := range []bool{false, true} {
+		scroll := NewScrollBuilder(client, indexName).
+			Term("status", "active").
+			Size(20).
+			Slices(4).
+			Concurrency(2)
+		if usePIT {
+			scroll.UsePIT()
+		}
+
+		var mu sync.Mutex
+		seen := make(map[string]bool)
+		err := scroll.Each(ctx, func(hit Hit) error {
+			mu.Lock()
+			defer mu.Unlock()
+			seen[hit.ID] = true
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("并发遍历失败 (PIT=%v): %v", usePIT, err)
+		}
+		// 1、4、7 ... 共 100 个 active 文档，切片之间不重复
+		if len(seen) != 100 {
+			t.Errorf("期望遍历100个文档 (PIT=%v)，实际: %d", usePIT, len(seen))
+		}
+	}
+
+	t.Logf("✓ 切片并发遍历成功")
+}
+
+// TestScrollBuilder_StreamCancel 测试通过 ctx 取消通道遍历
+func TestScrollBuilder_StreamCancel(t *testing.T) {
+	client := createScrollTestClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	indexName := "test_scroll_stream_cancel"
+	defer NewIndexBuilder(client, indexName).Delete(ctx)
+
+	prepareScrollTestData(t, client, indexName, 100)
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	hits := make(chan Hit)
+	done := make(chan error, 1)
+	go func() {
+		done <- NewScrollBuilder(client, indexName).Size(10).Slices(2).Stream(streamCtx, hits)
+	}()
+
+	// 只读取 5 条就取消
+	for i := 0; i < 5; i++ {
+		<-hits
+	}
+	cancel()
+	for range hits {
+		// Stream 结束时关闭通道
+	}
+
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("期望返回 context.Canceled，实际: %v", err)
+	}
+
+	t.Logf("✓ 取消遍历成功")
+}
+
+// TestScrollBuilder_All 测试迭代器遍历和提前结束
+func TestScrollBuilder_All(t *testing.T) {
+	client := createScrollTestClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	indexName := "test_scroll_all"
+	defer NewIndexBuilder(client, indexName).Delete(ctx)
+
+	prepareScrollTestData(t, client, indexName, 100)
+
+	count := 0
+	for hit, err := range NewScrollBuilder(client, indexName).Size(30).All(ctx) {
+		if err != nil {
+			t.Fatalf("遍历失败: %v", err)
+		}
+		if hit.ID == "" {
+			t.Errorf("命中缺少 _id")
+		}
+		count++
+	}
+	if count != 100 {
+		t.Errorf("期望遍历100个文档，实际: %d", count)
+	}
+
+	// 提前结束时自动清除 scroll 上下文
+	count = 0
+	for _, err := range NewScrollBuilder(client, indexName).Size(10).Slices(2).All(ctx) {
+		if err != nil {
+			t.Fatalf("遍历失败: %v", err)
+		}
+		count++
+		if count == 15 {
+			break
+		}
+	}
+
+	// 类型化版本
+	type scrollDoc struct {
+		ID     int    `json:"id"`
+		Status string `json:"status"`
+	}
+	active := 0
+	for doc, err := range ScrollAllAs[scrollDoc](ctx, NewScrollBuilder(client, indexName).Term("status", "active")) {
+		if err != nil {
+			t.Fatalf("遍历失败: %v", err)
+		}
+		if doc.Status != "active" {
+			t.Errorf("期望 active，实际: %s", doc.Status)
+		}
+		active++
+	}
+	if active != 34 {
+		t.Errorf("期望34个 active 文档，实际: %d", active)
+	}
+
+	t.Logf("✓ 迭代器遍历成功")
+}
+
+// TestScrollBuilder_EachDeadline 测试 ctx 超时立即中止正在进行的 scroll 请求，并清除 scroll 上下文
+func TestScrollBuilder_EachDeadline(t *testing.T) {
+	var cleared int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 读完请求体后服务端才能感知连接关闭
+		io.Copy(io.Discard, r.Body)
+		switch {
+		case r.Method == http.MethodDelete:
+			atomic.AddInt32(&cleared, 1)
+			w.Write([]byte(`{"succeeded":true,"num_freed":1}`))
+		case r.URL.Path == "/_search/scroll":
+			// 翻页请求一直不返回，直到请求被取消
+			select {
+			case <-r.Context().Done():
+			case <-time.After(10 * time.Second):
+			}
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"_scroll_id":"scroll-1","hits":{"total":{"value":2},"hits":[{"_id":"1","_source":{}}]}}`))
+		}
+	}))
+	defer server.Close()
+
+	esClient, err := client.New(
+		config.WithAddresses(server.URL),
+		config.WithLogger(slog.New(slog.DiscardHandler)),
+	)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer esClient.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	count := 0
+	err = NewScrollBuilder(esClient, "test").Each(ctx, func(hit Hit) error {
+		count++
+		return nil
+	})
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("应该返回 context.DeadlineExceeded，实际 %v", err)
+	}
+	if elapsed > 2*time.Second {
+		t.Fatalf("超时后应该立即返回，实际耗时 %v", elapsed)
+	}
+	if count != 1 {
+		t.Errorf("应该处理 1 条命中，实际 %d", count)
+	}
+	if atomic.LoadInt32(&cleared) != 1 {
+		t.Errorf("超时后应该清除 scroll 上下文")
+	}
+
+	t.Logf("✓ 超时后 %v 返回并清除 scroll 上下文", elapsed)
+}
+
+// TestScrollBuilder_UsePITClosesLatestID 测试切片共享 PIT 时使用并关闭 ES 最新返回的 PIT id
+func TestScrollBuilder_UsePITClosesLatestID(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		searches int
+		usedIDs  = make(map[string]int)
+		closedID string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.URL.Path == "/test/_pit":
+			w.Write([]byte(`{"id":"pit-0"}`))
+		case r.Method == http.MethodDelete:
+			closedID, _ = body["id"].(string)
+			w.Write([]byte(`{"succeeded":true,"num_freed":1}`))
+		default:
+			pit, _ := body["pit"].(map[string]interface{})
+			id, _ := pit["id"].(string)
+			usedIDs[id]++
+
+			// 每次搜索都返回新的 PIT id，每个切片只有一页数据
+			searches++
+			newID := fmt.Sprintf("pit-%d", searches)
+			if _, ok := body["search_after"]; ok {
+				fmt.Fprintf(w, `{"pit_id":%q,"hits":{"hits":[]}}`, newID)
+				return
+			}
+			fmt.Fprintf(w, `{"pit_id":%q,"hits":{"hits":[{"_id":"1","_source":{},"sort":[1]}]}}`, newID)
+		}
+	}))
+	defer server.Close()
+
+	esClient, err := client.New(
+		config.WithAddresses(server.URL),
+		config.WithLogger(slog.New(slog.DiscardHandler)),
+	)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer esClient.Close()
+
+	count := 0
+	err = NewScrollBuilder(esClient, "test").Slices(2).Concurrency(1).UsePIT().Each(context.Background(), func(hit Hit) error {
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("遍历失败: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("应该遍历 2 条命中，实际 %d", count)
+	}
+
+	// 切片依次执行：第二个切片应该使用第一个切片拿到的新 id
+	if searches != 4 || usedIDs["pit-0"] != 1 {
+		t.Errorf("只有第一次搜索使用初始 PIT id，实际搜索 %d 次，使用情况 %v", searches, usedIDs)
+	}
+	if closedID != fmt.Sprintf("pit-%d", searches) {
+		t.Errorf("应该关闭最新的 PIT id pit-%d，实际关闭 %q", searches, closedID)
+	}
+
+	t.Logf("✓ 关闭最新的 PIT id: %s", closedID)
+}
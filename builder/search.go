@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	"github.com/Kirby980/go-es/client"
+	"github.com/Kirby980/go-es/query"
 )
 
 // SearchBuilder 搜索构建器
@@ -18,6 +19,7 @@ type SearchBuilder struct {
 	must                []map[string]interface{}
 	should              []map[string]interface{}
 	mustNot             []map[string]interface{}
+	rootQuery           query.Query // Query 设置的查询
 	minimumShouldMatch  interface{} // 最少匹配 should 条件数量
 	from                int
 	size                int
@@ -25,8 +27,12 @@ type SearchBuilder struct {
 	aggs                map[string]interface{}
 	source              []string
 	highlight           map[string]interface{}
-	minScore            *float64 // 最小评分
-	debug               bool     // 调试模式标志
+	minScore            *float64               // 最小评分
+	searchAfter         []interface{}          // 上一页最后一个文档的 sort 值
+	pit                 *pointInTime           // PIT 设置的 point in time
+	extra               map[string]interface{} // Extra 设置的顶层键
+	err                 error                  // RawQuery、FromJSON 的解析错误
+	debug               bool                   // 调试模式标志
 }
 
 // NewSearchBuilder 创建搜索构建器
@@ -43,6 +49,64 @@ func NewSearchBuilder(c *client.Client, index string) *SearchBuilder {
 	}
 }
 
+// Query 设置查询（query 包构建的查询节点）
+// 与 Match、Term 等链式方法添加的条件同时使用时，以 bool.must 组合
+func (b *SearchBuilder) Query(q query.Query) *SearchBuilder {
+	b.rootQuery = q
+	return b
+}
+
+// RawQuery 使用原始 JSON 设置查询，如 json.RawMessage(`{"match": {"title": "go"}}`)
+// 与 Match、Term 等链式方法添加的条件同时使用时，以 bool.must 组合
+func (b *SearchBuilder) RawQuery(raw json.RawMessage) *SearchBuilder {
+	q, err := rawQuery(raw)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	b.rootQuery = query.Raw(q)
+	return b
+}
+
+// Extra 设置请求体的顶层键，用于链式方法未覆盖的参数，如 track_total_hits、timeout、collapse、rescore
+// 与构建器生成的同名键（query、from、size 等）冲突时以构建器为准；Count 不使用这些键
+func (b *SearchBuilder) Extra(key string, value interface{}) *SearchBuilder {
+	b.extra = setExtra(b.extra, key, value)
+	return b
+}
+
+// FromJSON 加载保存的搜索请求体
+// query、from、size、aggs 写入构建器（之后仍可以继续链式添加条件），其余键等同于 Extra
+func (b *SearchBuilder) FromJSON(data []byte) *SearchBuilder {
+	body, err := decodeJSONObject(data)
+	if err != nil {
+		b.err = err
+		return b
+	}
+
+	for key, value := range body {
+		switch key {
+		case "query":
+			b.rootQuery, err = jsonQuery(value)
+		case "from":
+			b.from, err = jsonInt(key, value)
+		case "size":
+			b.size, err = jsonInt(key, value)
+		case "aggs", "aggregations":
+			err = mergeAggs(b.aggs, key, value)
+		case "pit":
+			b.pit, err = jsonPIT(value)
+		default:
+			b.extra = setExtra(b.extra, key, value)
+		}
+		if err != nil {
+			b.err = fmt.Errorf("加载请求体失败: %w", err)
+			return b
+		}
+	}
+	return b
+}
+
 // Match 添加 match 查询
 func (b *SearchBuilder) Match(field string, value interface{}) *SearchBuilder {
 	b.must = append(b.must, map[string]interface{}{
@@ -382,6 +446,61 @@ func (b *SearchBuilder) Sort(field string, order string) *SearchBuilder {
 	return b
 }
 
+// SearchAfter 设置 search_after 值（上一页最后一个文档的 sort 值），需要同时设置 Sort
+// 深度分页建议配合 PIT 使用，分页期间的写入不会影响结果
+func (b *SearchBuilder) SearchAfter(values ...interface{}) *SearchBuilder {
+	b.searchAfter = values
+	return b
+}
+
+// PIT 在已打开的 PIT 上查询（如多个构建器共享 OpenPIT 打开的 PIT），keepAlive 为每次查询延长的有效期
+// 设置了 Sort 时，排序末尾自动追加 _shard_doc，保证排序值唯一
+func (b *SearchBuilder) PIT(id, keepAlive string) *SearchBuilder {
+	b.pit = &pointInTime{id: id, keepAlive: keepAlive}
+	return b
+}
+
+// OpenPIT 在构建器的索引上打开 PIT，之后的查询都在该 PIT 上执行，用完后调用 ClosePIT
+func (b *SearchBuilder) OpenPIT(ctx context.Context, keepAlive string) error {
+	// 如果启用调试模式，由客户端打印请求和响应信息（不重置，后续查询仍然打印）
+	if b.debug {
+		ctx = client.WithDebug(ctx)
+	}
+
+	id, err := OpenPIT(ctx, b.client, b.index, keepAlive)
+	if err != nil {
+		return fmt.Errorf("打开 PIT 失败: %w", err)
+	}
+	b.pit = &pointInTime{id: id, keepAlive: keepAlive}
+	return nil
+}
+
+// ClosePIT 关闭当前使用的 PIT，没有使用 PIT 时直接返回
+func (b *SearchBuilder) ClosePIT(ctx context.Context) error {
+	if b.pit == nil {
+		return nil
+	}
+
+	// 如果启用调试模式，由客户端打印请求和响应信息
+	if b.debug {
+		ctx = client.WithDebug(ctx)
+	}
+
+	if err := ClosePIT(ctx, b.client, b.pit.id); err != nil {
+		return fmt.Errorf("关闭 PIT 失败: %w", err)
+	}
+	b.pit = nil
+	return nil
+}
+
+// PITID 返回当前 PIT id（每次查询后更新为响应中的新 id），没有使用 PIT 时返回空字符串
+func (b *SearchBuilder) PITID() string {
+	if b.pit == nil {
+		return ""
+	}
+	return b.pit.id
+}
+
 // Source 设置返回字段
 func (b *SearchBuilder) Source(fields ...string) *SearchBuilder {
 	b.source = fields
@@ -412,8 +531,9 @@ func (b *SearchBuilder) Highlight(fields ...string) *SearchBuilder {
 
 // SearchResponse 搜索响应
 type SearchResponse struct {
-	Took     int  `json:"took"`
-	TimedOut bool `json:"timed_out"`
+	PitID    string `json:"pit_id,omitempty"` // 使用 PIT 时返回的新 PIT id
+	Took     int    `json:"took"`
+	TimedOut bool   `json:"timed_out"`
 	Shards   struct {
 		Total      int `json:"total"`
 		Successful int `json:"successful"`
@@ -431,6 +551,7 @@ type SearchResponse struct {
 			ID        string                 `json:"_id"`
 			Score     float64                `json:"_score"`
 			Source    map[string]interface{} `json:"_source"`
+			Sort      []interface{}          `json:"sort,omitempty"` // 设置 Sort 时每个文档的排序值
 			Highlight map[string][]string    `json:"highlight,omitempty"`
 		} `json:"hits"`
 	} `json:"hits"`
@@ -441,28 +562,9 @@ type SearchResponse struct {
 func (b *SearchBuilder) Build() map[string]interface{} {
 	body := make(map[string]interface{})
 
-	// 构建 bool 查询
-	if len(b.must) > 0 || len(b.filters) > 0 || len(b.should) > 0 || len(b.mustNot) > 0 {
-		boolQuery := make(map[string]interface{})
-		if len(b.must) > 0 {
-			boolQuery["must"] = b.must
-		}
-		if len(b.filters) > 0 {
-			boolQuery["filter"] = b.filters
-		}
-		if len(b.should) > 0 {
-			boolQuery["should"] = b.should
-		}
-		if len(b.mustNot) > 0 {
-			boolQuery["must_not"] = b.mustNot
-		}
-		// 最少匹配 should 条件数量
-		if b.minimumShouldMatch != nil {
-			boolQuery["minimum_should_match"] = b.minimumShouldMatch
-		}
-		body["query"] = map[string]interface{}{
-			"bool": boolQuery,
-		}
+	// 构建查询条件
+	if q := buildQuery(b.rootQuery, b.must, b.filters, b.should, b.mustNot, b.minimumShouldMatch); q != nil {
+		body["query"] = q
 	}
 
 	// 最小评分
@@ -476,7 +578,21 @@ func (b *SearchBuilder) Build() map[string]interface{} {
 
 	// 排序
 	if len(b.sort) > 0 {
-		body["sort"] = b.sort
+		if b.pit != nil {
+			body["sort"] = withShardDocTiebreaker(b.sort)
+		} else {
+			body["sort"] = b.sort
+		}
+	}
+
+	// search_after 分页
+	if len(b.searchAfter) > 0 {
+		body["search_after"] = b.searchAfter
+	}
+
+	// PIT
+	if b.pit != nil {
+		body["pit"] = b.pit.build()
 	}
 
 	// 返回字段
@@ -494,7 +610,7 @@ func (b *SearchBuilder) Build() map[string]interface{} {
 		body["highlight"] = b.highlight
 	}
 
-	return body
+	return mergeExtra(body, b.extra)
 }
 
 // Debug 启用调试模式（链式调用）
@@ -503,23 +619,6 @@ func (b *SearchBuilder) Debug() *SearchBuilder {
 	return b
 }
 
-// printDebug 打印请求调试信息
-func (b *SearchBuilder) printDebug(method, path string, body interface{}) {
-	fmt.Printf("\n[ES Debug] %s %s\n", method, path)
-	if body != nil {
-		data, _ := json.MarshalIndent(body, "", "  ")
-		fmt.Printf("Request Body:\n%s\n", string(data))
-	}
-}
-
-// printResponse 打印响应调试信息
-func (b *SearchBuilder) printResponse(respBody []byte) {
-	var pretty interface{}
-	json.Unmarshal(respBody, &pretty)
-	data, _ := json.MarshalIndent(pretty, "", "  ")
-	fmt.Printf("Response:\n%s\n\n", string(data))
-}
-
 // resetDebug 执行后重置debug标志（让每次调用可以独立控制）
 func (b *SearchBuilder) resetDebug() {
 	b.debug = false
@@ -527,29 +626,27 @@ func (b *SearchBuilder) resetDebug() {
 
 // Do 执行搜索
 func (b *SearchBuilder) Do(ctx context.Context) (*SearchResponse, error) {
-	path := fmt.Sprintf("/%s/_search", b.index)
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	path := searchPath(b.index, b.pit)
 	body := b.Build()
 
-	// 如果启用调试模式，打印请求信息
+	// 如果启用调试模式，由客户端打印请求和响应信息
 	if b.debug {
-		b.printDebug("POST", path, body)
+		ctx = client.WithDebug(ctx)
 		defer b.resetDebug()
 	}
 
-	respBody, err := b.client.Do(ctx, http.MethodPost, path, body)
-	if err != nil {
+	// 流式解析响应，命中逐条解码
+	var resp SearchResponse
+	if err := streamSearch(ctx, b.client, http.MethodPost, path, body, &resp, appendHit(&resp.Hits.Hits)); err != nil {
 		return nil, err
 	}
 
-	// 如果启用调试模式，打印响应信息
-	if b.debug {
-		b.printResponse(respBody)
-	}
-
-	var resp SearchResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
-		return nil, fmt.Errorf("解析响应失败: %w", err)
-	}
+	// 使用 PIT 时，后续查询使用响应中的新 PIT id
+	b.pit.update(resp.PitID)
 
 	return &resp, nil
 }
@@ -567,32 +664,21 @@ type CountResponse struct {
 
 // Count 执行计数查询（只返回匹配文档数量，不返回文档内容）
 func (b *SearchBuilder) Count(ctx context.Context) (int64, error) {
+	if b.err != nil {
+		return 0, b.err
+	}
+
 	path := fmt.Sprintf("/%s/_count", b.index)
 
 	// 构建查询条件（不需要分页、排序等）
 	body := make(map[string]interface{})
-	if len(b.must) > 0 || len(b.filters) > 0 || len(b.should) > 0 || len(b.mustNot) > 0 {
-		boolQuery := make(map[string]interface{})
-		if len(b.must) > 0 {
-			boolQuery["must"] = b.must
-		}
-		if len(b.filters) > 0 {
-			boolQuery["filter"] = b.filters
-		}
-		if len(b.should) > 0 {
-			boolQuery["should"] = b.should
-		}
-		if len(b.mustNot) > 0 {
-			boolQuery["must_not"] = b.mustNot
-		}
-		body["query"] = map[string]interface{}{
-			"bool": boolQuery,
-		}
+	if q := buildQuery(b.rootQuery, b.must, b.filters, b.should, b.mustNot, b.minimumShouldMatch); q != nil {
+		body["query"] = q
 	}
 
-	// 如果启用调试模式，打印请求信息
+	// 如果启用调试模式，由客户端打印请求和响应信息
 	if b.debug {
-		b.printDebug("POST", path, body)
+		ctx = client.WithDebug(ctx)
 		defer b.resetDebug()
 	}
 
@@ -601,13 +687,8 @@ func (b *SearchBuilder) Count(ctx context.Context) (int64, error) {
 		return 0, err
 	}
 
-	// 如果启用调试模式，打印响应信息
-	if b.debug {
-		b.printResponse(respBody)
-	}
-
 	var resp CountResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := b.client.Codec().Unmarshal(respBody, &resp); err != nil {
 		return 0, fmt.Errorf("解析响应失败: %w", err)
 	}
 
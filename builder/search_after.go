@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	"github.com/Kirby980/go-es/client"
+	"github.com/Kirby980/go-es/query"
 )
 
 // SearchAfterBuilder Search After深度分页构建器
@@ -18,6 +19,7 @@ type SearchAfterBuilder struct {
 	must               []map[string]interface{}
 	should             []map[string]interface{}
 	mustNot            []map[string]interface{}
+	rootQuery          query.Query // Query 设置的查询
 	minimumShouldMatch interface{} // 最少匹配 should 条件数量
 	size               int
 	sort               []map[string]interface{}
@@ -25,8 +27,18 @@ type SearchAfterBuilder struct {
 	source             []string
 	highlight          map[string]interface{}
 	minScore           *float64
+	pit                *pointInTime           // PIT 设置的 point in time
+	ownsPIT            bool                   // PIT 由 OpenPIT 打开，All 结束时关闭
+	extra              map[string]interface{} // Extra 设置的顶层键
+	err                error                  // RawQuery、FromJSON 的解析错误
 	debug              bool
-	lastResponse       *SearchAfterResponse // 保存上次响应用于自动获取下一页
+	lastPage           *searchAfterPage // 上一页的信息，用于自动获取下一页
+}
+
+// searchAfterPage 上一页的文档数量和最后一个文档的 sort 值
+type searchAfterPage struct {
+	hits     int
+	lastSort []interface{}
 }
 
 // NewSearchAfterBuilder 创建SearchAfter构建器
@@ -44,6 +56,69 @@ func NewSearchAfterBuilder(c *client.Client, index string) *SearchAfterBuilder {
 	}
 }
 
+// Query 设置查询（query 包构建的查询节点）
+// 与 Match、Term 等链式方法添加的条件同时使用时，以 bool.must 组合
+func (b *SearchAfterBuilder) Query(q query.Query) *SearchAfterBuilder {
+	b.rootQuery = q
+	return b
+}
+
+// RawQuery 使用原始 JSON 设置查询
+// 与 Match、Term 等链式方法添加的条件同时使用时，以 bool.must 组合
+func (b *SearchAfterBuilder) RawQuery(raw json.RawMessage) *SearchAfterBuilder {
+	q, err := rawQuery(raw)
+	if err != nil {
+		b.err = err
+		return b
+	}
+	b.rootQuery = query.Raw(q)
+	return b
+}
+
+// Extra 设置请求体的顶层键，如 track_total_hits、timeout、collapse
+// 与构建器生成的同名键（query、size、sort、search_after 等）冲突时以构建器为准
+func (b *SearchAfterBuilder) Extra(key string, value interface{}) *SearchAfterBuilder {
+	b.extra = setExtra(b.extra, key, value)
+	return b
+}
+
+// FromJSON 加载保存的搜索请求体
+// query、size、sort、search_after 写入构建器，其余键等同于 Extra
+func (b *SearchAfterBuilder) FromJSON(data []byte) *SearchAfterBuilder {
+	body, err := decodeJSONObject(data)
+	if err != nil {
+		b.err = err
+		return b
+	}
+
+	for key, value := range body {
+		switch key {
+		case "query":
+			b.rootQuery, err = jsonQuery(value)
+		case "size":
+			b.size, err = jsonInt(key, value)
+		case "sort":
+			b.sort, err = jsonSort(value)
+		case "pit":
+			b.pit, err = jsonPIT(value)
+			b.ownsPIT = false
+		case "search_after":
+			values, ok := value.([]interface{})
+			if !ok {
+				err = fmt.Errorf("search_after 必须是数组，实际为 %T", value)
+			}
+			b.searchAfter = values
+		default:
+			b.extra = setExtra(b.extra, key, value)
+		}
+		if err != nil {
+			b.err = fmt.Errorf("加载请求体失败: %w", err)
+			return b
+		}
+	}
+	return b
+}
+
 // Match 添加 match 查询条件
 func (b *SearchAfterBuilder) Match(field string, value interface{}) *SearchAfterBuilder {
 	b.must = append(b.must, map[string]interface{}{
@@ -176,6 +251,57 @@ func (b *SearchAfterBuilder) SearchAfter(values ...interface{}) *SearchAfterBuil
 	return b
 }
 
+// PIT 在已打开的 PIT 上查询（如多个构建器共享 OpenPIT 打开的 PIT），keepAlive 为每次查询延长的有效期
+// 使用 PIT 时排序末尾自动追加 _shard_doc，保证排序值唯一
+func (b *SearchAfterBuilder) PIT(id, keepAlive string) *SearchAfterBuilder {
+	b.pit = &pointInTime{id: id, keepAlive: keepAlive}
+	b.ownsPIT = false
+	return b
+}
+
+// OpenPIT 在构建器的索引上打开 PIT，之后的查询都在该 PIT 上执行，用完后调用 ClosePIT
+func (b *SearchAfterBuilder) OpenPIT(ctx context.Context, keepAlive string) error {
+	// 如果启用调试模式，由客户端打印请求和响应信息（不重置，后续查询仍然打印）
+	if b.debug {
+		ctx = client.WithDebug(ctx)
+	}
+
+	id, err := OpenPIT(ctx, b.client, b.index, keepAlive)
+	if err != nil {
+		return fmt.Errorf("打开 PIT 失败: %w", err)
+	}
+	b.pit = &pointInTime{id: id, keepAlive: keepAlive}
+	b.ownsPIT = true
+	return nil
+}
+
+// ClosePIT 关闭当前使用的 PIT，没有使用 PIT 时直接返回
+func (b *SearchAfterBuilder) ClosePIT(ctx context.Context) error {
+	if b.pit == nil {
+		return nil
+	}
+
+	// 如果启用调试模式，由客户端打印请求和响应信息
+	if b.debug {
+		ctx = client.WithDebug(ctx)
+	}
+
+	if err := ClosePIT(ctx, b.client, b.pit.id); err != nil {
+		return fmt.Errorf("关闭 PIT 失败: %w", err)
+	}
+	b.pit = nil
+	b.ownsPIT = false
+	return nil
+}
+
+// PITID 返回当前 PIT id（每次查询后更新为响应中的新 id），没有使用 PIT 时返回空字符串
+func (b *SearchAfterBuilder) PITID() string {
+	if b.pit == nil {
+		return ""
+	}
+	return b.pit.id
+}
+
 // Source 指定返回的字段
 func (b *SearchAfterBuilder) Source(fields ...string) *SearchAfterBuilder {
 	b.source = fields
@@ -206,23 +332,6 @@ func (b *SearchAfterBuilder) Debug() *SearchAfterBuilder {
 	return b
 }
 
-// printDebug 打印请求调试信息
-func (b *SearchAfterBuilder) printDebug(method, path string, body interface{}) {
-	fmt.Printf("\n[ES Debug] %s %s\n", method, path)
-	if body != nil {
-		data, _ := json.MarshalIndent(body, "", "  ")
-		fmt.Printf("Request Body:\n%s\n", string(data))
-	}
-}
-
-// printResponse 打印响应调试信息
-func (b *SearchAfterBuilder) printResponse(respBody []byte) {
-	var pretty interface{}
-	json.Unmarshal(respBody, &pretty)
-	data, _ := json.MarshalIndent(pretty, "", "  ")
-	fmt.Printf("Response:\n%s\n\n", string(data))
-}
-
 // resetDebug 执行后重置debug标志（让每次调用可以独立控制）
 func (b *SearchAfterBuilder) resetDebug() {
 	b.debug = false
@@ -233,33 +342,19 @@ func (b *SearchAfterBuilder) Build() map[string]interface{} {
 	body := make(map[string]interface{})
 
 	// 构建查询条件
-	if len(b.must) > 0 || len(b.filters) > 0 || len(b.should) > 0 || len(b.mustNot) > 0 {
-		boolQuery := make(map[string]interface{})
-		if len(b.must) > 0 {
-			boolQuery["must"] = b.must
-		}
-		if len(b.filters) > 0 {
-			boolQuery["filter"] = b.filters
-		}
-		if len(b.should) > 0 {
-			boolQuery["should"] = b.should
-		}
-		if len(b.mustNot) > 0 {
-			boolQuery["must_not"] = b.mustNot
-		}
-		if b.minimumShouldMatch != nil {
-			boolQuery["minimum_should_match"] = b.minimumShouldMatch
-		}
-		body["query"] = map[string]interface{}{
-			"bool": boolQuery,
-		}
+	if q := buildQuery(b.rootQuery, b.must, b.filters, b.should, b.mustNot, b.minimumShouldMatch); q != nil {
+		body["query"] = q
 	}
 
 	// 添加 size
 	body["size"] = b.size
 
 	// 添加排序（Search After 必须有排序）
-	if len(b.sort) > 0 {
+	if b.pit != nil {
+		// 使用 PIT 时以 _shard_doc 作为最后的排序字段，代价比 _id 低
+		body["sort"] = withShardDocTiebreaker(b.sort)
+		body["pit"] = b.pit.build()
+	} else if len(b.sort) > 0 {
 		body["sort"] = b.sort
 	} else {
 		// 默认按 _id 排序（如果用户没指定）
@@ -288,13 +383,14 @@ func (b *SearchAfterBuilder) Build() map[string]interface{} {
 		body["min_score"] = *b.minScore
 	}
 
-	return body
+	return mergeExtra(body, b.extra)
 }
 
 // SearchAfterResponse Search After响应
 type SearchAfterResponse struct {
-	Took     int  `json:"took"`
-	TimedOut bool `json:"timed_out"`
+	PitID    string `json:"pit_id,omitempty"` // 使用 PIT 时返回的新 PIT id
+	Took     int    `json:"took"`
+	TimedOut bool   `json:"timed_out"`
 	Shards   struct {
 		Total      int `json:"total"`
 		Successful int `json:"successful"`
@@ -320,12 +416,16 @@ type SearchAfterResponse struct {
 
 // Do 执行查询
 func (b *SearchAfterBuilder) Do(ctx context.Context) (*SearchAfterResponse, error) {
-	path := fmt.Sprintf("/%s/_search", b.index)
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	path := searchPath(b.index, b.pit)
 	body := b.Build()
 
-	// 如果启用调试模式，打印请求信息
+	// 如果启用调试模式，由客户端打印请求和响应信息
 	if b.debug {
-		b.printDebug("POST", path, body)
+		ctx = client.WithDebug(ctx)
 		defer b.resetDebug()
 	}
 
@@ -334,44 +434,53 @@ func (b *SearchAfterBuilder) Do(ctx context.Context) (*SearchAfterResponse, erro
 		return nil, err
 	}
 
-	// 如果启用调试模式，打印响应信息
-	if b.debug {
-		b.printResponse(respBody)
-	}
-
 	var resp SearchAfterResponse
-	if err := json.Unmarshal(respBody, &resp); err != nil {
+	if err := b.client.Codec().Unmarshal(respBody, &resp); err != nil {
 		return nil, fmt.Errorf("解析响应失败: %w", err)
 	}
 
-	// 保存响应供 Next() 使用
-	b.lastResponse = &resp
+	// 使用 PIT 时，后续查询使用响应中的新 PIT id
+	b.pit.update(resp.PitID)
+
+	// 保存分页信息供 Next() 使用
+	var lastSort []interface{}
+	if n := len(resp.Hits.Hits); n > 0 {
+		lastSort = resp.Hits.Hits[n-1].Sort
+	}
+	b.lastPage = &searchAfterPage{hits: len(resp.Hits.Hits), lastSort: lastSort}
 
 	return &resp, nil
 }
 
 // Next 获取下一页数据（自动使用上一次响应的最后一个文档的 sort 值）
 func (b *SearchAfterBuilder) Next(ctx context.Context) (*SearchAfterResponse, error) {
-	if b.lastResponse == nil {
-		return nil, fmt.Errorf("请先调用 Do() 方法初始化查询")
+	if err := b.advance(); err != nil {
+		return nil, err
+	}
+
+	// 执行下一页查询
+	return b.Do(ctx)
+}
+
+// advance 使用上一页最后一个文档的 sort 值设置 search_after
+func (b *SearchAfterBuilder) advance() error {
+	if b.lastPage == nil {
+		return fmt.Errorf("请先调用 Do() 方法初始化查询")
 	}
 
 	// 检查是否还有数据
-	if len(b.lastResponse.Hits.Hits) == 0 {
-		return nil, fmt.Errorf("已经没有更多数据")
+	if b.lastPage.hits == 0 {
+		return fmt.Errorf("已经没有更多数据")
 	}
 
 	// 获取最后一个文档的 sort 值
-	lastHit := b.lastResponse.Hits.Hits[len(b.lastResponse.Hits.Hits)-1]
-	if len(lastHit.Sort) == 0 {
-		return nil, fmt.Errorf("响应中没有 sort 字段，请确保查询包含排序")
+	if len(b.lastPage.lastSort) == 0 {
+		return fmt.Errorf("响应中没有 sort 字段，请确保查询包含排序")
 	}
 
 	// 设置 search_after
-	b.searchAfter = lastHit.Sort
-
-	// 执行下一页查询
-	return b.Do(ctx)
+	b.searchAfter = b.lastPage.lastSort
+	return nil
 }
 
 // HasMore 判断是否还有更多数据
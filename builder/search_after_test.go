@@ -741,3 +741,127 @@ func TestSearchAfterBuilder_LargeDataset(t *testing.T) {
 		t.Errorf("期望10页，实际: %d", pageCount)
 	}
 }
+
+// TestSearchAfterBuilder_PIT 测试在 PIT 上分页，分页期间写入的文档不影响结果
+func TestSearchAfterBuilder_PIT(t *testing.T) {
+	client := createSearchAfterTestClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	indexName := "test_search_after_pit"
+	defer NewIndexBuilder(client, indexName).Delete(ctx)
+
+	prepareSearchAfterTestData(t, client, indexName, 30)
+
+	searchAfter := NewSearchAfterBuilder(client, indexName).
+		Sort("price", "asc").
+		Size(10)
+	if err := searchAfter.OpenPIT(ctx, "1m"); err != nil {
+		t.Fatalf("打开 PIT 失败: %v", err)
+	}
+	defer searchAfter.ClosePIT(ctx)
+
+	resp, err := searchAfter.Do(ctx)
+	if err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if resp.PitID == "" || searchAfter.PITID() != resp.PitID {
+		t.Errorf("应该使用响应中的新 PIT id")
+	}
+	// 排序值末尾是自动追加的 _shard_doc
+	if sort := resp.Hits.Hits[0].Sort; len(sort) != 2 {
+		t.Errorf("排序值应该包含 price 和 _shard_doc，实际: %v", sort)
+	}
+
+	// 分页期间写入新文档
+	_, err = NewBulkBuilder(client).Index(indexName).
+		Add("", "", map[string]interface{}{"id": 31, "category": "books", "price": 5.0}).
+		Add("", "", map[string]interface{}{"id": 32, "category": "books", "price": 500.0}).
+		Do(ctx)
+	if err != nil {
+		t.Fatalf("写入文档失败: %v", err)
+	}
+	time.Sleep(2 * time.Second) // 等待索引刷新
+
+	totalFetched := len(resp.Hits.Hits)
+	for searchAfter.HasMore(resp) {
+		resp, err = searchAfter.Next(ctx)
+		if err != nil {
+			t.Fatalf("获取下一页失败: %v", err)
+		}
+		totalFetched += len(resp.Hits.Hits)
+	}
+
+	if totalFetched != 30 {
+		t.Errorf("PIT 固定了数据视图，期望获取30条，实际: %d", totalFetched)
+	}
+
+	if err := searchAfter.ClosePIT(ctx); err != nil {
+		t.Errorf("关闭 PIT 失败: %v", err)
+	}
+	if searchAfter.PITID() != "" {
+		t.Errorf("关闭后不应该再有 PIT id")
+	}
+
+	t.Logf("✓ PIT 分页成功，共 %d 条", totalFetched)
+}
+
+// TestSearchAfterBuilder_All 测试迭代器遍历，结束时关闭 PIT
+func TestSearchAfterBuilder_All(t *testing.T) {
+	client := createSearchAfterTestClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	indexName := "test_search_after_all"
+	defer NewIndexBuilder(client, indexName).Delete(ctx)
+
+	prepareSearchAfterTestData(t, client, indexName, 45)
+
+	searchAfter := NewSearchAfterBuilder(client, indexName).
+		Sort("price", "asc").
+		Size(10)
+	if err := searchAfter.OpenPIT(ctx, "1m"); err != nil {
+		t.Fatalf("打开 PIT 失败: %v", err)
+	}
+
+	count := 0
+	var lastPrice float64
+	for hit, err := range searchAfter.All(ctx) {
+		if err != nil {
+			t.Fatalf("遍历失败: %v", err)
+		}
+		price := hit.Source["price"].(float64)
+		if price < lastPrice {
+			t.Errorf("结果应该按价格升序，%v 在 %v 之后", price, lastPrice)
+		}
+		lastPrice = price
+		count++
+	}
+	if count != 45 {
+		t.Errorf("期望遍历45个文档，实际: %d", count)
+	}
+	if searchAfter.PITID() != "" {
+		t.Errorf("遍历结束后应该关闭 OpenPIT 打开的 PIT")
+	}
+
+	// 类型化版本，提前结束
+	type product struct {
+		ID    int     `json:"id"`
+		Price float64 `json:"price"`
+	}
+	var products []product
+	for p, err := range SearchAfterAllAs[product](ctx, NewSearchAfterBuilder(client, indexName).Sort("id", "asc").Size(4)) {
+		if err != nil {
+			t.Fatalf("遍历失败: %v", err)
+		}
+		products = append(products, p)
+		if len(products) == 6 {
+			break
+		}
+	}
+	if len(products) != 6 || products[5].ID != 6 {
+		t.Errorf("期望前6个文档，实际: %+v", products)
+	}
+
+	t.Logf("✓ 迭代器遍历成功")
+}
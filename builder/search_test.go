@@ -2,10 +2,12 @@ package builder
 
 import (
 	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/Kirby980/go-es/client"
+	"github.com/Kirby980/go-es/query"
 )
 
 // 准备搜索测试数据
@@ -616,3 +618,191 @@ func TestSearchBuilder_Build(t *testing.T) {
 	t.Logf("✓ 查询 DSL 构建成功")
 	t.Logf("DSL: %+v", dsl)
 }
+
+// TestSearchBuilder_QueryDSL 测试同一个 query 包查询用于搜索、计数、滚动和删除
+func TestSearchBuilder_QueryDSL(t *testing.T) {
+	client := createTestClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	indexName := "test_search_query_dsl"
+	prepareSearchTestData(t, client, indexName)
+	defer func() {
+		_ = NewIndexBuilder(client, indexName).Delete(ctx)
+	}()
+
+	// 价格不低于 500 的苹果产品：iPhone、iPad、MacBook
+	appleProducts := query.Bool().
+		Filter(query.Term("tags", "apple")).
+		Filter(query.Range("price").Gte(500))
+
+	resp, err := NewSearchBuilder(client, indexName).Query(appleProducts).Do(ctx)
+	if err != nil {
+		t.Fatalf("搜索失败: %v", err)
+	}
+	if resp.Hits.Total.Value != 3 {
+		t.Errorf("搜索应该找到 3 条结果，实际 %d", resp.Hits.Total.Value)
+	}
+
+	count, err := NewSearchBuilder(client, indexName).Query(appleProducts).Count(ctx)
+	if err != nil {
+		t.Fatalf("计数失败: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("计数应该为 3，实际 %d", count)
+	}
+
+	// 与链式方法组合：再排除电子产品
+	resp, err = NewSearchBuilder(client, indexName).
+		Query(appleProducts).
+		MustNot("category", "electronics").
+		Do(ctx)
+	if err != nil {
+		t.Fatalf("组合搜索失败: %v", err)
+	}
+	if resp.Hits.Total.Value != 2 {
+		t.Errorf("组合搜索应该找到 2 条结果，实际 %d", resp.Hits.Total.Value)
+	}
+
+	scroll := NewScrollBuilder(client, indexName).Query(appleProducts).Size(2)
+	scrollResp, err := scroll.Do(ctx)
+	if err != nil {
+		t.Fatalf("滚动查询失败: %v", err)
+	}
+	_ = scroll.Clear(ctx)
+	if scrollResp.Hits.Total.Value != 3 || len(scrollResp.Hits.Hits) != 2 {
+		t.Errorf("滚动查询应该共 3 条、首批 2 条，实际共 %d 条、首批 %d 条",
+			scrollResp.Hits.Total.Value, len(scrollResp.Hits.Hits))
+	}
+
+	deleted, err := NewDeleteByQueryBuilder(client, indexName).Query(appleProducts).Refresh().Do(ctx)
+	if err != nil {
+		t.Fatalf("按查询删除失败: %v", err)
+	}
+	if deleted.Deleted != 3 {
+		t.Errorf("应该删除 3 个文档，实际 %d", deleted.Deleted)
+	}
+
+	t.Logf("✓ 同一查询用于搜索、计数、滚动和删除成功")
+}
+
+func TestSearchBuilder_FromJSON(t *testing.T) {
+	client := createTestClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	indexName := "test_search_from_json"
+	prepareSearchTestData(t, client, indexName)
+	defer func() {
+		_ = NewIndexBuilder(client, indexName).Delete(ctx)
+	}()
+
+	// 保存的查询：苹果产品，每页 2 条，附带平均价格聚合
+	saved := []byte(`{
+		"query": {"term": {"tags": "apple"}},
+		"size": 2,
+		"track_total_hits": true,
+		"aggs": {"avg_price": {"avg": {"field": "price"}}}
+	}`)
+
+	resp, err := NewSearchBuilder(client, indexName).
+		FromJSON(saved).
+		Range("price", 500, nil).
+		Extra("collapse", map[string]interface{}{"field": "category"}).
+		Do(ctx)
+	if err != nil {
+		t.Fatalf("搜索失败: %v", err)
+	}
+	// iPhone、iPad、MacBook 分属 3 个品类，按品类折叠后仍各占一条
+	if resp.Hits.Total.Value != 3 {
+		t.Errorf("应该找到 3 条结果，实际 %d", resp.Hits.Total.Value)
+	}
+	if len(resp.Hits.Hits) != 2 {
+		t.Errorf("应该返回 2 条结果，实际 %d", len(resp.Hits.Hits))
+	}
+	if _, ok := resp.Aggregations["avg_price"]; !ok {
+		t.Errorf("应该返回 avg_price 聚合")
+	}
+
+	// RawQuery 与 query 包的查询等价
+	count, err := NewSearchBuilder(client, indexName).
+		RawQuery(json.RawMessage(`{"term": {"tags": "apple"}}`)).
+		Count(ctx)
+	if err != nil {
+		t.Fatalf("计数失败: %v", err)
+	}
+	if count != 4 {
+		t.Errorf("计数应该为 4，实际 %d", count)
+	}
+
+	// JSON 格式错误在执行时返回
+	if _, err := NewSearchBuilder(client, indexName).RawQuery(json.RawMessage(`{"term":`)).Do(ctx); err == nil {
+		t.Errorf("无效的 JSON 应该返回错误")
+	}
+
+	// 同一份请求体加载到其他构建器
+	aggResp, err := NewAggregationBuilder(client, indexName).FromJSON(saved).Size(0).Do(ctx)
+	if err != nil {
+		t.Fatalf("聚合失败: %v", err)
+	}
+	if _, ok := aggResp.Aggregations["avg_price"]; !ok {
+		t.Errorf("聚合应该返回 avg_price")
+	}
+
+	deleted, err := NewDeleteByQueryBuilder(client, indexName).
+		FromJSON([]byte(`{"query": {"term": {"category": "wearables"}}}`)).
+		Refresh().
+		Do(ctx)
+	if err != nil {
+		t.Fatalf("按查询删除失败: %v", err)
+	}
+	if deleted.Deleted != 1 {
+		t.Errorf("应该删除 1 个文档，实际 %d", deleted.Deleted)
+	}
+
+	t.Logf("✓ 加载保存的查询成功")
+}
+
+func TestSearchBuilder_PIT(t *testing.T) {
+	client := createTestClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	indexName := "test_search_pit"
+	prepareSearchTestData(t, client, indexName)
+	defer func() {
+		_ = NewIndexBuilder(client, indexName).Delete(ctx)
+	}()
+
+	pitID, err := OpenPIT(ctx, client, indexName, "1m")
+	if err != nil {
+		t.Fatalf("打开 PIT 失败: %v", err)
+	}
+
+	search := NewSearchBuilder(client, indexName).
+		PIT(pitID, "1m").
+		Sort("price", "desc").
+		Size(2)
+	defer search.ClosePIT(ctx) // 关闭最新的 PIT id
+
+	var prices []interface{}
+	for {
+		resp, err := search.Do(ctx)
+		if err != nil {
+			t.Fatalf("搜索失败: %v", err)
+		}
+		if len(resp.Hits.Hits) == 0 {
+			break
+		}
+		for _, hit := range resp.Hits.Hits {
+			prices = append(prices, hit.Source["price"])
+		}
+		search.SearchAfter(resp.Hits.Hits[len(resp.Hits.Hits)-1].Sort...)
+	}
+
+	if len(prices) != 5 {
+		t.Errorf("应该遍历 5 个文档，实际 %d", len(prices))
+	}
+
+	t.Logf("✓ PIT 分页成功: %v", prices)
+}
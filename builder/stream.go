@@ -0,0 +1,158 @@
+package builder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Kirby980/go-es/client"
+	"github.com/Kirby980/go-es/config"
+)
+
+// streamSearch 执行搜索类请求并流式解析响应
+func streamSearch(ctx context.Context, c *client.Client, method, path string, body interface{}, resp interface{}, onHit func(raw json.RawMessage, codec config.Codec) error) error {
+	codec := c.Codec()
+	data, err := codec.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("序列化请求体失败: %w", err)
+	}
+
+	stream, err := c.Stream(ctx, method, path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+
+	if err := decodeSearchStream(stream, codec, resp, onHit); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	return nil
+}
+
+// decodeSearchStream 流式解析搜索类响应
+// hits.hits 中的命中逐条读取后交给 onHit 解码，不需要先读取完整响应体；其余字段解码到 resp
+// encoding/json 只负责拆分 JSON 结构，值的解码都由 codec 完成
+func decodeSearchStream(r io.Reader, codec config.Codec, resp interface{}, onHit func(raw json.RawMessage, codec config.Codec) error) error {
+	dec := json.NewDecoder(r)
+	fields := make(map[string]json.RawMessage)
+	hitsFields := make(map[string]json.RawMessage)
+
+	err := decodeObject(dec, func(key string) error {
+		if key != "hits" {
+			return decodeRaw(dec, fields, key)
+		}
+		return decodeObject(dec, func(key string) error {
+			if key != "hits" {
+				return decodeRaw(dec, hitsFields, key)
+			}
+			return decodeArray(dec, func() error {
+				var raw json.RawMessage
+				if err := dec.Decode(&raw); err != nil {
+					return err
+				}
+				return onHit(raw, codec)
+			})
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(hitsFields) > 0 {
+		data, err := json.Marshal(hitsFields)
+		if err != nil {
+			return err
+		}
+		fields["hits"] = data
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return codec.Unmarshal(data, resp)
+}
+
+// appendHit 返回将命中逐条解码并追加到切片的函数
+func appendHit[T any](hits *[]T) func(raw json.RawMessage, codec config.Codec) error {
+	return func(raw json.RawMessage, codec config.Codec) error {
+		var hit T
+		if err := codec.Unmarshal(raw, &hit); err != nil {
+			return err
+		}
+		*hits = append(*hits, hit)
+		return nil
+	}
+}
+
+// decodeObject 逐个读取 JSON 对象的键，由 fn 解码对应的值
+func decodeObject(dec *json.Decoder, fn func(key string) error) error {
+	if ok, err := openDelim(dec, '{'); !ok {
+		return err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := tok.(string)
+		if !ok {
+			return fmt.Errorf("期望对象键，实际为 %v", tok)
+		}
+		if err := fn(key); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
+
+// decodeArray 逐个读取 JSON 数组元素，由 fn 解码
+func decodeArray(dec *json.Decoder, fn func() error) error {
+	if ok, err := openDelim(dec, '['); !ok {
+		return err
+	}
+	for dec.More() {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
+
+// decodeRaw 读取原始 JSON 值
+func decodeRaw(dec *json.Decoder, fields map[string]json.RawMessage, key string) error {
+	var raw json.RawMessage
+	if err := dec.Decode(&raw); err != nil {
+		return err
+	}
+	fields[key] = raw
+	return nil
+}
+
+// openDelim 读取起始分隔符，值为 null 时返回 false
+func openDelim(dec *json.Decoder, delim json.Delim) (bool, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return false, err
+	}
+	if tok == nil {
+		return false, nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != delim {
+		return false, fmt.Errorf("期望 %v，实际为 %v", delim, tok)
+	}
+	return true, nil
+}
+
+// expectDelim 读取并校验分隔符
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != delim {
+		return fmt.Errorf("期望 %v，实际为 %v", delim, tok)
+	}
+	return nil
+}
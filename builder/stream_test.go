@@ -0,0 +1,171 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Kirby980/go-es/config"
+)
+
+// streamTestResponse 测试用的搜索响应
+type streamTestResponse struct {
+	Took int `json:"took"`
+	Hits struct {
+		Total struct {
+			Value int `json:"value"`
+		} `json:"total"`
+		MaxScore float64 `json:"max_score"`
+	} `json:"hits"`
+	Aggregations map[string]interface{} `json:"aggregations"`
+}
+
+func TestDecodeSearchStream(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantIDs   []string
+		wantTook  int
+		wantTotal int
+		wantErr   bool
+	}{
+		{
+			name:      "完整响应",
+			body:      `{"took":5,"hits":{"total":{"value":2},"max_score":1.0,"hits":[{"_id":"1"},{"_id":"2"}]},"aggregations":{"n":{"value":1}}}`,
+			wantIDs:   []string{"1", "2"},
+			wantTook:  5,
+			wantTotal: 2,
+		},
+		{
+			name:      "hits 在其他字段之前",
+			body:      `{"hits":{"hits":[{"_id":"1"}],"total":{"value":1}},"took":3}`,
+			wantIDs:   []string{"1"},
+			wantTook:  3,
+			wantTotal: 1,
+		},
+		{name: "hits.hits 为 null", body: `{"took":1,"hits":{"total":{"value":0},"hits":null}}`, wantTook: 1},
+		{name: "hits 为 null", body: `{"took":1,"hits":null}`, wantTook: 1},
+		{name: "没有 hits", body: `{"took":1}`, wantTook: 1},
+		{name: "空数组", body: `{"took":1,"hits":{"hits":[]}}`, wantTook: 1},
+		{name: "hits 不是对象", body: `{"hits":[1]}`, wantErr: true},
+		{name: "响应被截断", body: `{"took":1,"hits":{"hits":[{"_id":"1"}`, wantErr: true},
+		{name: "不是 JSON", body: `error`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		var ids []string
+		var resp streamTestResponse
+		err := decodeSearchStream(strings.NewReader(tt.body), config.JSONCodec{}, &resp, func(raw json.RawMessage, codec config.Codec) error {
+			var hit struct {
+				ID string `json:"_id"`
+			}
+			if err := codec.Unmarshal(raw, &hit); err != nil {
+				return err
+			}
+			ids = append(ids, hit.ID)
+			return nil
+		})
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: 错误不符合预期: %v", tt.name, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if strings.Join(ids, ",") != strings.Join(tt.wantIDs, ",") {
+			t.Errorf("%s: 命中应该为 %v，实际 %v", tt.name, tt.wantIDs, ids)
+		}
+		if resp.Took != tt.wantTook || resp.Hits.Total.Value != tt.wantTotal {
+			t.Errorf("%s: 其余字段解码不正确: took=%d total=%d", tt.name, resp.Took, resp.Hits.Total.Value)
+		}
+	}
+}
+
+func TestDecodeSearchStream_HitError(t *testing.T) {
+	errStop := errors.New("stop")
+	var calls int
+	body := `{"hits":{"hits":[{"_id":"1"},{"_id":"2"},{"_id":"3"}]}}`
+
+	// onHit 返回错误时停止解析并返回该错误
+	err := decodeSearchStream(strings.NewReader(body), config.JSONCodec{}, &streamTestResponse{}, func(raw json.RawMessage, codec config.Codec) error {
+		calls++
+		if calls == 2 {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) || calls != 2 {
+		t.Fatalf("应该在第 2 个命中返回错误，实际 %v（调用 %d 次）", err, calls)
+	}
+}
+
+func TestDecodeSearchStream_UseNumber(t *testing.T) {
+	body := `{"took":1,"hits":{"max_score":1.5,"hits":[{"_source":{"id":9007199254740993}}]},"aggregations":{"max_id":{"value":9007199254740993}}}`
+
+	var hits []struct {
+		Source map[string]interface{} `json:"_source"`
+	}
+	var resp streamTestResponse
+	if err := decodeSearchStream(strings.NewReader(body), config.JSONCodec{UseNumber: true}, &resp, appendHit(&hits)); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+
+	if len(hits) != 1 || hits[0].Source["id"] != json.Number("9007199254740993") {
+		t.Fatalf("命中中的大整数应该保留精度，实际 %v", hits)
+	}
+	maxID := resp.Aggregations["max_id"].(map[string]interface{})["value"]
+	if maxID != json.Number("9007199254740993") {
+		t.Fatalf("聚合中的大整数应该保留精度，实际 %v", maxID)
+	}
+	if resp.Hits.MaxScore != 1.5 {
+		t.Fatalf("结构体字段应该正常解码，实际 %v", resp.Hits.MaxScore)
+	}
+
+	t.Logf("✓ 使用 UseNumber 流式解析")
+}
+
+// countingCodec 统计调用次数的编解码器
+type countingCodec struct {
+	config.JSONCodec
+	marshals, decodes int32
+}
+
+func (c *countingCodec) Marshal(v interface{}) ([]byte, error) {
+	atomic.AddInt32(&c.marshals, 1)
+	return c.JSONCodec.Marshal(v)
+}
+
+func (c *countingCodec) Unmarshal(data []byte, v interface{}) error {
+	atomic.AddInt32(&c.decodes, 1)
+	return c.JSONCodec.Unmarshal(data, v)
+}
+
+func (c *countingCodec) NewDecoder(r io.Reader) config.Decoder {
+	atomic.AddInt32(&c.decodes, 1)
+	return c.JSONCodec.NewDecoder(r)
+}
+
+// TestSearchBuilder_Codec 测试搜索构建器使用客户端的编解码器
+func TestSearchBuilder_Codec(t *testing.T) {
+	codec := &countingCodec{JSONCodec: config.JSONCodec{UseNumber: true}}
+	esClient := newTypedStubClient(t, `{"took":1,"hits":{"total":{"value":1},"hits":[{"_id":"1","_source":{"id":9007199254740993}}]}}`,
+		config.WithCodec(codec))
+
+	resp, err := NewSearchBuilder(esClient, "test").MatchAll().Do(context.Background())
+	if err != nil {
+		t.Fatalf("搜索失败: %v", err)
+	}
+	if len(resp.Hits.Hits) != 1 || resp.Hits.Hits[0].Source["id"] != json.Number("9007199254740993") {
+		t.Fatalf("命中应该使用客户端的编解码器解码，实际 %v", resp.Hits.Hits)
+	}
+	// 请求体序列化 1 次；命中和其余字段各解码 1 次
+	if codec.marshals != 1 || codec.decodes != 2 {
+		t.Fatalf("应该序列化 1 次、解码 2 次，实际 %d、%d", codec.marshals, codec.decodes)
+	}
+
+	t.Logf("✓ 搜索使用客户端的编解码器")
+}
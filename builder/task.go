@@ -0,0 +1,230 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Kirby980/go-es/client"
+)
+
+// TaskHandle 异步任务句柄（wait_for_completion=false 时 ES 返回的任务）
+type TaskHandle struct {
+	client   *client.Client
+	ID       string // 任务 ID，格式为 节点ID:任务编号
+	endpoint string // 任务对应的接口（_reindex、_update_by_query、_delete_by_query），用于调整限速
+}
+
+// NewTaskHandle 根据任务 ID 创建任务句柄，用于跟踪其他进程启动的任务
+func NewTaskHandle(c *client.Client, id string) *TaskHandle {
+	return &TaskHandle{client: c, ID: id}
+}
+
+// TaskProgress 任务进度（reindex、update_by_query、delete_by_query 任务的 status 字段）
+type TaskProgress struct {
+	Total                int64   `json:"total"`
+	Created              int64   `json:"created"`
+	Updated              int64   `json:"updated"`
+	Deleted              int64   `json:"deleted"`
+	Batches              int64   `json:"batches"`
+	VersionConflicts     int64   `json:"version_conflicts"`
+	Noops                int64   `json:"noops"`
+	ThrottledMillis      int64   `json:"throttled_millis"`
+	RequestsPerSecond    float64 `json:"requests_per_second"`
+	ThrottledUntilMillis int64   `json:"throttled_until_millis"`
+}
+
+// Done 已处理的文档数
+func (p TaskProgress) Done() int64 {
+	return p.Created + p.Updated + p.Deleted + p.Noops + p.VersionConflicts
+}
+
+// TaskInfo 任务信息
+type TaskInfo struct {
+	Node               string       `json:"node"`
+	ID                 int64        `json:"id"`
+	Type               string       `json:"type"`
+	Action             string       `json:"action"`
+	Description        string       `json:"description"`
+	Status             TaskProgress `json:"status"`
+	StartTimeInMillis  int64        `json:"start_time_in_millis"`
+	RunningTimeInNanos int64        `json:"running_time_in_nanos"`
+	Cancellable        bool         `json:"cancellable"`
+	Cancelled          bool         `json:"cancelled"`
+}
+
+// TaskStatus 任务状态
+type TaskStatus struct {
+	Completed bool                   `json:"completed"`
+	Task      TaskInfo               `json:"task"`
+	Response  json.RawMessage        `json:"response,omitempty"` // 任务完成后的响应（与同步执行的响应相同）
+	Error     map[string]interface{} `json:"error,omitempty"`    // 任务失败时的错误
+}
+
+// Status 获取任务状态
+func (h *TaskHandle) Status(ctx context.Context) (*TaskStatus, error) {
+	path := fmt.Sprintf("/_tasks/%s", h.ID)
+	respBody, err := h.client.Do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var status TaskStatus
+	if err := h.client.Codec().Unmarshal(respBody, &status); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+
+	return &status, nil
+}
+
+// Wait 按间隔轮询任务状态直到任务完成
+// 任务本身失败时返回错误；批次中的文档失败需要通过 Response 中的 failures 判断
+func (h *TaskHandle) Wait(ctx context.Context, pollInterval time.Duration) (*TaskStatus, error) {
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		status, err := h.Status(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if status.Completed {
+			if status.Error != nil {
+				return status, fmt.Errorf("任务 %s 执行失败: %v", h.ID, status.Error["reason"])
+			}
+			return status, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// DecodeResponse 将任务完成后的响应解析到 v（如 *ReindexResponse）
+func (s *TaskStatus) DecodeResponse(c *client.Client, v interface{}) error {
+	if len(s.Response) == 0 {
+		return fmt.Errorf("任务尚未完成")
+	}
+	if err := c.Codec().Unmarshal(s.Response, v); err != nil {
+		return fmt.Errorf("解析任务响应失败: %w", err)
+	}
+	return nil
+}
+
+// Cancel 取消任务，已处理的文档不会回滚
+func (h *TaskHandle) Cancel(ctx context.Context) error {
+	path := fmt.Sprintf("/_tasks/%s/_cancel", h.ID)
+	respBody, err := h.client.Do(ctx, http.MethodPost, path, nil)
+	if err != nil {
+		return err
+	}
+	return taskFailures(h.client, respBody)
+}
+
+// Rethrottle 调整运行中任务的限速（每秒处理的文档数），-1 表示不限制
+// 降低限速在当前批次完成后生效，提高限速立即生效
+func (h *TaskHandle) Rethrottle(ctx context.Context, rps float64) error {
+	endpoint, err := h.resolveEndpoint(ctx)
+	if err != nil {
+		return err
+	}
+
+	params := url.Values{}
+	params.Set("requests_per_second", strconv.FormatFloat(rps, 'f', -1, 64))
+	path := requestPath(fmt.Sprintf("/%s/%s/_rethrottle", endpoint, h.ID), params)
+
+	respBody, err := h.client.Do(ctx, http.MethodPost, path, nil)
+	if err != nil {
+		return err
+	}
+	return taskFailures(h.client, respBody)
+}
+
+// taskEndpoints 任务 action 对应的接口
+var taskEndpoints = map[string]string{
+	"indices:data/write/reindex":        "_reindex",
+	"indices:data/write/update/byquery": "_update_by_query",
+	"indices:data/write/delete/byquery": "_delete_by_query",
+}
+
+// resolveEndpoint 返回任务对应的接口，通过 NewTaskHandle 创建的句柄需要查询任务 action
+func (h *TaskHandle) resolveEndpoint(ctx context.Context) (string, error) {
+	if h.endpoint != "" {
+		return h.endpoint, nil
+	}
+
+	status, err := h.Status(ctx)
+	if err != nil {
+		return "", err
+	}
+	endpoint, ok := taskEndpoints[status.Task.Action]
+	if !ok {
+		return "", fmt.Errorf("任务 %s (%s) 不支持调整限速", h.ID, status.Task.Action)
+	}
+	h.endpoint = endpoint
+	return endpoint, nil
+}
+
+// taskFailures 检查任务管理接口响应中的失败信息
+func taskFailures(c *client.Client, respBody []byte) error {
+	var resp struct {
+		NodeFailures []map[string]interface{} `json:"node_failures"`
+		TaskFailures []map[string]interface{} `json:"task_failures"`
+	}
+	if err := c.Codec().Unmarshal(respBody, &resp); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+
+	failures := append(resp.NodeFailures, resp.TaskFailures...)
+	if len(failures) > 0 {
+		return fmt.Errorf("任务操作失败: %v", failures[0])
+	}
+	return nil
+}
+
+// taskResponse wait_for_completion=false 的响应
+type taskResponse struct {
+	Task string `json:"task"`
+}
+
+// startTask 以 wait_for_completion=false 提交请求并返回任务句柄
+// 提交请求不重试：ES 已经接受请求但响应丢失时，重试会再启动一个任务，重复执行更新、删除或重建
+func startTask(ctx context.Context, c *client.Client, method, path string, body interface{}) (*TaskHandle, error) {
+	respBody, err := c.Do(client.WithoutRetry(ctx), method, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp taskResponse
+	if err := c.Codec().Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	if resp.Task == "" {
+		return nil, fmt.Errorf("响应中缺少任务 ID")
+	}
+
+	// 从路径中取出接口名，如 /products/_update_by_query?... 中的 _update_by_query
+	endpoint, _, _ := strings.Cut(path, "?")
+	endpoint = endpoint[strings.LastIndex(endpoint, "/")+1:]
+
+	return &TaskHandle{client: c, ID: resp.Task, endpoint: endpoint}, nil
+}
+
+// requestPath 拼接请求路径和查询参数
+func requestPath(path string, params url.Values) string {
+	if len(params) == 0 {
+		return path
+	}
+	return path + "?" + params.Encode()
+}
@@ -0,0 +1,159 @@
+package builder
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Kirby980/go-es/client"
+	"github.com/Kirby980/go-es/config"
+)
+
+// TestUpdateByQueryBuilder_Start 测试异步按查询更新
+func TestUpdateByQueryBuilder_Start(t *testing.T) {
+	client := createTestClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	indexName := "test_task_update_by_query"
+	prepareReindexSource(t, client, indexName)
+	defer func() {
+		_ = NewIndexBuilder(client, indexName).Delete(ctx)
+	}()
+
+	task, err := NewUpdateByQueryBuilder(client, indexName).
+		Term("author", "alice").
+		Set("views", 0).
+		ConflictsProceed().
+		ScrollSize(2).
+		RequestsPerSecond(1).
+		Refresh().
+		Start(ctx)
+	if err != nil {
+		t.Fatalf("启动更新任务失败: %v", err)
+	}
+	t.Logf("任务 ID: %s", task.ID)
+
+	// 限速运行中调整为不限速
+	if err := task.Rethrottle(ctx, -1); err != nil {
+		t.Fatalf("调整限速失败: %v", err)
+	}
+
+	status, err := task.Wait(ctx, 200*time.Millisecond)
+	if err != nil {
+		t.Fatalf("等待任务失败: %v", err)
+	}
+	if status.Task.Status.Total != 5 {
+		t.Errorf("任务应该处理 5 个文档，实际 %d", status.Task.Status.Total)
+	}
+
+	var resp UpdateByQueryResponse
+	if err := status.DecodeResponse(client, &resp); err != nil {
+		t.Fatalf("解析任务响应失败: %v", err)
+	}
+	if resp.Updated != 5 {
+		t.Errorf("应该更新 5 个文档，实际 %d", resp.Updated)
+	}
+	t.Logf("✓ 异步更新成功: %+v", status.Task.Status)
+}
+
+// TestDeleteByQueryBuilder_StartAndCancel 测试异步按查询删除与取消任务
+func TestDeleteByQueryBuilder_StartAndCancel(t *testing.T) {
+	client := createTestClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	indexName := "test_task_delete_by_query"
+	prepareReindexSource(t, client, indexName)
+	defer func() {
+		_ = NewIndexBuilder(client, indexName).Delete(ctx)
+	}()
+
+	// 每秒只处理 1 个文档，保证取消时任务仍在运行
+	task, err := NewDeleteByQueryBuilder(client, indexName).
+		Exists("title").
+		ScrollSize(1).
+		RequestsPerSecond(1).
+		Start(ctx)
+	if err != nil {
+		t.Fatalf("启动删除任务失败: %v", err)
+	}
+
+	if err := task.Cancel(ctx); err != nil {
+		t.Fatalf("取消任务失败: %v", err)
+	}
+
+	status, err := task.Wait(ctx, 200*time.Millisecond)
+	if err != nil {
+		t.Fatalf("等待任务失败: %v", err)
+	}
+	if !status.Task.Cancelled {
+		t.Error("任务应该已被取消")
+	}
+	if status.Task.Status.Deleted >= 10 {
+		t.Errorf("取消后不应删除全部文档，实际删除 %d", status.Task.Status.Deleted)
+	}
+
+	// 同步执行：MaxDocs 限制删除数量
+	resp, err := NewDeleteByQueryBuilder(client, indexName).
+		Exists("title").
+		MaxDocs(2).
+		Refresh().
+		Do(ctx)
+	if err != nil {
+		t.Fatalf("按查询删除失败: %v", err)
+	}
+	if resp.Deleted > 2 {
+		t.Errorf("最多删除 2 个文档，实际 %d", resp.Deleted)
+	}
+	t.Logf("✓ 取消任务成功，已删除 %d 个文档", status.Task.Status.Deleted)
+}
+
+// TestStartTask_NoRetry 测试异步任务提交失败时不重试，避免重复启动非幂等任务
+func TestStartTask_NoRetry(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusGatewayTimeout)
+		w.Write([]byte(`{"error":{"type":"timeout","reason":"gateway timeout"},"status":504}`))
+	}))
+	defer server.Close()
+
+	esClient, err := client.New(
+		config.WithAddresses(server.URL),
+		config.WithRetry(3, time.Millisecond),
+		config.WithLogger(slog.New(slog.DiscardHandler)),
+	)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer esClient.Close()
+	ctx := context.Background()
+
+	starts := map[string]func() (*TaskHandle, error){
+		"update_by_query": func() (*TaskHandle, error) {
+			return NewUpdateByQueryBuilder(esClient, "test").Script("ctx._source.n++", nil).Start(ctx)
+		},
+		"delete_by_query": func() (*TaskHandle, error) {
+			return NewDeleteByQueryBuilder(esClient, "test").Term("status", "expired").Start(ctx)
+		},
+		"reindex": func() (*TaskHandle, error) {
+			return NewReindexBuilder(esClient).Source("src").Dest("dst").Start(ctx)
+		},
+	}
+	for name, start := range starts {
+		atomic.StoreInt32(&requests, 0)
+		if _, err := start(); err == nil {
+			t.Errorf("%s: 应该返回错误", name)
+		}
+		if n := atomic.LoadInt32(&requests); n != 1 {
+			t.Errorf("%s: 应该只发送 1 次请求，实际 %d", name, n)
+		}
+	}
+
+	t.Logf("✓ 异步任务提交不重试")
+}
@@ -0,0 +1,336 @@
+package builder
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/Kirby980/go-es/client"
+	"github.com/Kirby980/go-es/config"
+)
+
+// ========== 类型化响应 ==========
+
+// TotalHits 命中总数
+type TotalHits struct {
+	Value    int    `json:"value"`
+	Relation string `json:"relation"`
+}
+
+// ShardsInfo 分片执行情况
+type ShardsInfo struct {
+	Total      int `json:"total"`
+	Successful int `json:"successful"`
+	Skipped    int `json:"skipped"`
+	Failed     int `json:"failed"`
+}
+
+// NestedIdentity 嵌套文档在父文档中的位置（inner_hits 中使用）
+type NestedIdentity struct {
+	Field  string          `json:"field"`
+	Offset int             `json:"offset"`
+	Nested *NestedIdentity `json:"_nested,omitempty"`
+}
+
+// TypedHit 类型化的搜索命中，_source 解码为 T
+type TypedHit[T any] struct {
+	Index     string               `json:"_index"`
+	ID        string               `json:"_id"`
+	Score     float64              `json:"_score"`
+	Source    T                    `json:"_source"`
+	Highlight map[string][]string  `json:"highlight,omitempty"`
+	Sort      []interface{}        `json:"sort,omitempty"`
+	Nested    *NestedIdentity      `json:"_nested,omitempty"`
+	InnerHits map[string]InnerHits `json:"inner_hits,omitempty"`
+
+	codec config.Codec // 解码命中使用的编解码器，InnerHitsAs 使用同一个编解码器
+}
+
+// Hit 搜索命中，_source 为 map（ScrollBuilder.Each、Stream 等使用）
+type Hit = TypedHit[map[string]interface{}]
+
+// TypedHits 类型化的命中列表
+type TypedHits[T any] struct {
+	Total    TotalHits     `json:"total"`
+	MaxScore float64       `json:"max_score"`
+	Hits     []TypedHit[T] `json:"hits"`
+}
+
+// InnerHits inner_hits 结果，通过 InnerHitsAs 解码为具体类型
+type InnerHits struct {
+	Hits TypedHits[RawSource] `json:"hits"`
+}
+
+// RawSource 未解码的 _source，inner_hits 的文档结构通常和外层不同，需要通过 InnerHitsAs 指定类型
+type RawSource = json.RawMessage
+
+// TypedSearchResponse 类型化的搜索响应（Search、Scroll、SearchAfter 通用）
+type TypedSearchResponse[T any] struct {
+	ScrollID     string                 `json:"_scroll_id,omitempty"`
+	PitID        string                 `json:"pit_id,omitempty"`
+	Took         int                    `json:"took"`
+	TimedOut     bool                   `json:"timed_out"`
+	Shards       ShardsInfo             `json:"_shards"`
+	Hits         TypedHits[T]           `json:"hits"`
+	Aggregations map[string]interface{} `json:"aggregations,omitempty"`
+}
+
+// Sources 返回所有命中的 _source
+func (r *TypedSearchResponse[T]) Sources() []T {
+	sources := make([]T, 0, len(r.Hits.Hits))
+	for _, hit := range r.Hits.Hits {
+		sources = append(sources, hit.Source)
+	}
+	return sources
+}
+
+// TypedGetResponse 类型化的获取文档响应
+type TypedGetResponse[T any] struct {
+	Index       string `json:"_index"`
+	ID          string `json:"_id"`
+	Version     int    `json:"_version"`
+	SeqNo       int64  `json:"_seq_no"`
+	PrimaryTerm int64  `json:"_primary_term"`
+	Found       bool   `json:"found"`
+	Source      T      `json:"_source"`
+}
+
+// TypedMGetResponse 类型化的批量获取响应
+type TypedMGetResponse[T any] struct {
+	Docs []TypedGetResponse[T] `json:"docs"`
+}
+
+// Found 返回找到的文档
+func (r *TypedMGetResponse[T]) Found() []T {
+	sources := make([]T, 0, len(r.Docs))
+	for _, doc := range r.Docs {
+		if doc.Found {
+			sources = append(sources, doc.Source)
+		}
+	}
+	return sources
+}
+
+// InnerHitsAs 将命中中指定名称的 inner_hits 解码为 U，使用与外层命中相同的编解码器
+func InnerHitsAs[U, T any](hit *TypedHit[T], name string) ([]TypedHit[U], error) {
+	inner, ok := hit.InnerHits[name]
+	if !ok {
+		return nil, nil
+	}
+
+	codec := hit.codec
+	if codec == nil {
+		codec = config.JSONCodec{}
+	}
+
+	hits := make([]TypedHit[U], 0, len(inner.Hits.Hits))
+	for _, raw := range inner.Hits.Hits {
+		h := TypedHit[U]{
+			Index:     raw.Index,
+			ID:        raw.ID,
+			Score:     raw.Score,
+			Highlight: raw.Highlight,
+			Sort:      raw.Sort,
+			Nested:    raw.Nested,
+			InnerHits: raw.InnerHits,
+			codec:     codec,
+		}
+		if len(raw.Source) > 0 {
+			if err := codec.Unmarshal(raw.Source, &h.Source); err != nil {
+				return nil, fmt.Errorf("解析inner_hits失败: %w", err)
+			}
+		}
+		hits = append(hits, h)
+	}
+	return hits, nil
+}
+
+// ========== 类型化查询 ==========
+
+// SearchAs 执行搜索，命中的 _source 解码为 T
+func SearchAs[T any](ctx context.Context, b *SearchBuilder) (*TypedSearchResponse[T], error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	path := searchPath(b.index, b.pit)
+	body := b.Build()
+
+	// 如果启用调试模式，由客户端打印请求和响应信息
+	if b.debug {
+		ctx = client.WithDebug(ctx)
+		defer b.resetDebug()
+	}
+
+	resp, err := typedSearch[T](ctx, b.client, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	// 使用 PIT 时，后续查询使用响应中的新 PIT id
+	b.pit.update(resp.PitID)
+	return resp, nil
+}
+
+// ScrollAs 执行第一次 scroll 查询，命中的 _source 解码为 T
+func ScrollAs[T any](ctx context.Context, b *ScrollBuilder) (*TypedSearchResponse[T], error) {
+	path, body, err := b.firstRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	// 如果启用调试模式，由客户端打印请求和响应信息
+	if b.debug {
+		ctx = client.WithDebug(ctx)
+		defer b.resetDebug()
+	}
+
+	resp, err := typedSearch[T](ctx, b.client, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	// 保存scroll ID供下次使用
+	b.scrollID = resp.ScrollID
+	return resp, nil
+}
+
+// ScrollNextAs 获取下一批 scroll 数据，命中的 _source 解码为 T
+func ScrollNextAs[T any](ctx context.Context, b *ScrollBuilder) (*TypedSearchResponse[T], error) {
+	path, body, err := b.nextRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	// 如果启用调试模式，由客户端打印请求和响应信息
+	if b.debug {
+		ctx = client.WithDebug(ctx)
+		defer b.resetDebug()
+	}
+
+	resp, err := typedSearch[T](ctx, b.client, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	// 更新scroll ID
+	b.scrollID = resp.ScrollID
+	return resp, nil
+}
+
+// SearchAfterAs 执行 search_after 查询，命中的 _source 解码为 T
+func SearchAfterAs[T any](ctx context.Context, b *SearchAfterBuilder) (*TypedSearchResponse[T], error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	path := searchPath(b.index, b.pit)
+	body := b.Build()
+
+	// 如果启用调试模式，由客户端打印请求和响应信息
+	if b.debug {
+		ctx = client.WithDebug(ctx)
+		defer b.resetDebug()
+	}
+
+	resp, err := typedSearch[T](ctx, b.client, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	// 使用 PIT 时，后续查询使用响应中的新 PIT id
+	b.pit.update(resp.PitID)
+
+	// 保存分页信息供 SearchAfterNextAs() 使用
+	var lastSort []interface{}
+	if n := len(resp.Hits.Hits); n > 0 {
+		lastSort = resp.Hits.Hits[n-1].Sort
+	}
+	b.lastPage = &searchAfterPage{hits: len(resp.Hits.Hits), lastSort: lastSort}
+
+	return resp, nil
+}
+
+// SearchAfterNextAs 获取下一页数据（自动使用上一页最后一个文档的 sort 值），命中的 _source 解码为 T
+func SearchAfterNextAs[T any](ctx context.Context, b *SearchAfterBuilder) (*TypedSearchResponse[T], error) {
+	if err := b.advance(); err != nil {
+		return nil, err
+	}
+	return SearchAfterAs[T](ctx, b)
+}
+
+// GetAs 获取文档，_source 解码为 T
+func GetAs[T any](ctx context.Context, b *DocumentBuilder) (*TypedGetResponse[T], error) {
+	if b.id == "" {
+		return nil, fmt.Errorf("获取文档需要指定 ID")
+	}
+
+	path := fmt.Sprintf("/%s/_doc/%s", b.index, b.id)
+
+	// 如果启用调试模式，由客户端打印请求和响应信息
+	if b.debug {
+		ctx = client.WithDebug(ctx)
+		defer b.resetDebug()
+	}
+
+	respBody, err := b.client.Do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp TypedGetResponse[T]
+	if err := b.client.Codec().Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+
+	return &resp, nil
+}
+
+// MGetAs 批量获取文档，_source 解码为 T
+func MGetAs[T any](ctx context.Context, b *MGetBuilder) (*TypedMGetResponse[T], error) {
+	path := fmt.Sprintf("/%s/_mget", b.index)
+	body := map[string]interface{}{
+		"ids": b.ids,
+	}
+
+	// 如果启用调试模式，由客户端打印请求和响应信息
+	if b.debug {
+		ctx = client.WithDebug(ctx)
+		defer b.resetDebug()
+	}
+
+	respBody, err := b.client.Do(ctx, http.MethodPost, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp TypedMGetResponse[T]
+	if err := b.client.Codec().Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+
+	return &resp, nil
+}
+
+// typedSearch 执行搜索类请求并流式解码为类型化响应
+func typedSearch[T any](ctx context.Context, c *client.Client, path string, body map[string]interface{}) (*TypedSearchResponse[T], error) {
+	var resp TypedSearchResponse[T]
+	if err := streamSearch(ctx, c, http.MethodPost, path, body, &resp, appendTypedHit(&resp.Hits.Hits)); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// appendTypedHit 同 appendHit，同时记录解码使用的编解码器
+func appendTypedHit[T any](hits *[]TypedHit[T]) func(raw json.RawMessage, codec config.Codec) error {
+	return func(raw json.RawMessage, codec config.Codec) error {
+		var hit TypedHit[T]
+		if err := codec.Unmarshal(raw, &hit); err != nil {
+			return err
+		}
+		hit.codec = codec
+		*hits = append(*hits, hit)
+		return nil
+	}
+}
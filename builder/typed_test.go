@@ -0,0 +1,200 @@
+package builder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kirby980/go-es/client"
+	"github.com/Kirby980/go-es/config"
+)
+
+// typedTestDoc 类型化查询测试文档
+type typedTestDoc struct {
+	Title  string   `json:"title"`
+	Author string   `json:"author"`
+	Views  int      `json:"views"`
+	Tags   []string `json:"tags"`
+}
+
+// TestTyped_SearchAndGet 测试类型化搜索和获取
+func TestTyped_SearchAndGet(t *testing.T) {
+	client := createTestClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	indexName := "test_typed_search"
+	prepareTestIndex(t, client, indexName)
+	defer func() {
+		_ = NewIndexBuilder(client, indexName).Delete(ctx)
+	}()
+
+	docs := []typedTestDoc{
+		{Title: "Go 并发编程", Author: "张三", Views: 100, Tags: []string{"go"}},
+		{Title: "Go 泛型入门", Author: "李四", Views: 200, Tags: []string{"go", "泛型"}},
+		{Title: "Rust 所有权", Author: "王五", Views: 300, Tags: []string{"rust"}},
+	}
+	bulk := NewBulkBuilder(client)
+	for i, doc := range docs {
+		bulk.AddFromStruct(indexName, strconv.Itoa(i+1), doc)
+	}
+	if _, err := bulk.Do(ctx); err != nil {
+		t.Fatalf("批量写入失败: %v", err)
+	}
+	time.Sleep(2 * time.Second) // 等待索引刷新
+
+	resp, err := SearchAs[typedTestDoc](ctx, NewSearchBuilder(client, indexName).
+		Term("tags", "go").
+		Sort("views", "asc").
+		Highlight("title"))
+	if err != nil {
+		t.Fatalf("类型化搜索失败: %v", err)
+	}
+	if resp.Hits.Total.Value != 2 {
+		t.Fatalf("期望命中 2 条, 实际=%d", resp.Hits.Total.Value)
+	}
+	if resp.Hits.Hits[0].Source.Author != "张三" {
+		t.Errorf("期望第一条 Author=张三, 实际=%s", resp.Hits.Hits[0].Source.Author)
+	}
+	if len(resp.Hits.Hits[0].Sort) == 0 {
+		t.Errorf("期望返回 sort 值")
+	}
+	t.Logf("✓ 类型化搜索成功: %+v", resp.Sources())
+
+	getResp, err := GetAs[typedTestDoc](ctx, NewDocumentBuilder(client, indexName).ID("3"))
+	if err != nil {
+		t.Fatalf("类型化获取失败: %v", err)
+	}
+	if !getResp.Found || getResp.Source.Views != 300 {
+		t.Errorf("期望 Views=300, 实际=%+v", getResp)
+	}
+
+	mgetResp, err := MGetAs[typedTestDoc](ctx, NewMGetBuilder(client, indexName).IDs("1", "2", "404"))
+	if err != nil {
+		t.Fatalf("类型化批量获取失败: %v", err)
+	}
+	if len(mgetResp.Found()) != 2 {
+		t.Errorf("期望找到 2 条, 实际=%d", len(mgetResp.Found()))
+	}
+	t.Logf("✓ 类型化获取成功: %+v", mgetResp.Found())
+}
+
+// TestTyped_Scroll 测试类型化 scroll
+func TestTyped_Scroll(t *testing.T) {
+	client := createTestClient(t)
+	defer client.Close()
+	ctx := context.Background()
+
+	indexName := "test_typed_scroll"
+	prepareTestIndex(t, client, indexName)
+	defer func() {
+		_ = NewIndexBuilder(client, indexName).Delete(ctx)
+	}()
+
+	bulk := NewBulkBuilder(client)
+	for i := 0; i < 25; i++ {
+		bulk.AddFromStruct(indexName, "", typedTestDoc{Title: "文档", Views: i})
+	}
+	if _, err := bulk.Do(ctx); err != nil {
+		t.Fatalf("批量写入失败: %v", err)
+	}
+	time.Sleep(2 * time.Second) // 等待索引刷新
+
+	scroll := NewScrollBuilder(client, indexName).Size(10)
+	defer scroll.Clear(ctx)
+
+	total := 0
+	resp, err := ScrollAs[typedTestDoc](ctx, scroll)
+	for err == nil && len(resp.Hits.Hits) > 0 {
+		total += len(resp.Hits.Hits)
+		resp, err = ScrollNextAs[typedTestDoc](ctx, scroll)
+	}
+	if err != nil {
+		t.Fatalf("类型化 scroll 失败: %v", err)
+	}
+	if total != 25 {
+		t.Errorf("期望遍历 25 条, 实际=%d", total)
+	}
+	t.Logf("✓ 类型化 scroll 成功: 共 %d 条", total)
+}
+
+// newTypedStubClient 创建连接到固定响应测试服务的客户端
+func newTypedStubClient(t *testing.T, response string, opts ...config.Option) *client.Client {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+
+	esClient, err := client.New(append([]config.Option{config.WithAddresses(server.URL)}, opts...)...)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	t.Cleanup(func() { esClient.Close() })
+	return esClient
+}
+
+// TestTyped_InnerHitsUseClientCodec 测试 inner_hits 使用客户端的编解码器解码
+func TestTyped_InnerHitsUseClientCodec(t *testing.T) {
+	esClient := newTypedStubClient(t, `{"hits":{"total":{"value":1},"hits":[{"_id":"1","_source":{"order_id":9007199254740993},
+		"inner_hits":{"items":{"hits":{"total":{"value":1},"hits":[{"_id":"1","_source":{"sku_id":9007199254740995}}]}}}}]}}`,
+		config.WithUseNumber())
+
+	resp, err := SearchAs[map[string]interface{}](context.Background(), NewSearchBuilder(esClient, "orders"))
+	if err != nil {
+		t.Fatalf("搜索失败: %v", err)
+	}
+	if _, ok := resp.Hits.Hits[0].Source["order_id"].(json.Number); !ok {
+		t.Fatalf("外层命中应该解码为 json.Number，实际 %T", resp.Hits.Hits[0].Source["order_id"])
+	}
+
+	items, err := InnerHitsAs[map[string]interface{}](&resp.Hits.Hits[0], "items")
+	if err != nil {
+		t.Fatalf("解析 inner_hits 失败: %v", err)
+	}
+	skuID, ok := items[0].Source["sku_id"].(json.Number)
+	if !ok || skuID.String() != "9007199254740995" {
+		t.Fatalf("inner_hits 应该与外层一样解码为 json.Number，实际 %T %v", items[0].Source["sku_id"], items[0].Source["sku_id"])
+	}
+
+	t.Logf("✓ inner_hits 使用客户端编解码器: %s", skuID)
+}
+
+// TestTyped_MGetAsDebug 测试 MGetAs 支持调试模式，执行后重置
+func TestTyped_MGetAsDebug(t *testing.T) {
+	var logs bytes.Buffer
+	esClient := newTypedStubClient(t, `{"docs":[{"_id":"1","found":true,"_source":{"title":"文档1"}}]}`,
+		config.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	ctx := context.Background()
+
+	mget := NewMGetBuilder(esClient, "docs").IDs("1").Debug()
+	resp, err := MGetAs[typedTestDoc](ctx, mget)
+	if err != nil {
+		t.Fatalf("批量获取失败: %v", err)
+	}
+	if found := resp.Found(); len(found) != 1 || found[0].Title != "文档1" {
+		t.Fatalf("应该找到文档1，实际 %v", found)
+	}
+	if !strings.Contains(logs.String(), "request_body") {
+		t.Fatalf("调试模式应该记录请求体，实际日志: %s", logs.String())
+	}
+
+	// 调试标志在执行后重置
+	logs.Reset()
+	if _, err := MGetAs[typedTestDoc](ctx, mget); err != nil {
+		t.Fatalf("批量获取失败: %v", err)
+	}
+	if logs.Len() != 0 {
+		t.Fatalf("第二次执行不应该输出调试日志，实际: %s", logs.String())
+	}
+
+	t.Logf("✓ MGetAs 调试模式")
+}
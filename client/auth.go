@@ -0,0 +1,72 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+
+	"github.com/Kirby980/go-es/config"
+)
+
+// newTLSConfig 根据配置创建 TLS 配置
+func newTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+
+	if len(cfg.CACert) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(cfg.CACert) {
+			return nil, fmt.Errorf("加载CA证书失败: 未找到有效的PEM证书")
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if len(cfg.ClientCert) > 0 || len(cfg.ClientKey) > 0 {
+		cert, err := tls.X509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("加载客户端证书失败: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// credentials 返回本次请求使用的凭据，配置了凭据提供函数时每次调用都重新获取
+func (c *Client) credentials(ctx context.Context) (config.Credentials, error) {
+	if c.config.CredentialsProvider != nil {
+		creds, err := c.config.CredentialsProvider(ctx)
+		if err != nil {
+			return config.Credentials{}, fmt.Errorf("获取认证凭据失败: %w", err)
+		}
+		return creds, nil
+	}
+
+	return config.Credentials{
+		Username:    c.config.Username,
+		Password:    c.config.Password,
+		APIKey:      c.config.APIKey,
+		BearerToken: c.config.BearerToken,
+	}, nil
+}
+
+// authorize 为请求设置认证头
+func (c *Client) authorize(req *http.Request) error {
+	creds, err := c.credentials(req.Context())
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case creds.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+creds.APIKey)
+	case creds.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+creds.BearerToken)
+	case creds.Username != "":
+		req.SetBasicAuth(creds.Username, creds.Password)
+	}
+	return nil
+}
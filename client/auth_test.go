@@ -0,0 +1,223 @@
+package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Kirby980/go-es/config"
+)
+
+// newAuthServer 创建记录 Authorization 头的测试服务
+func newAuthServer(t *testing.T, headers *[]string) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		*headers = append(*headers, r.Header.Get("Authorization"))
+		mu.Unlock()
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_Auth(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []config.Option
+		want string
+	}{
+		{"未认证", nil, ""},
+		{"Basic", []config.Option{config.WithAuth("elastic", "changeme")}, "Basic ZWxhc3RpYzpjaGFuZ2VtZQ=="},
+		{"API Key", []config.Option{config.WithAPIKey("id", "key")}, "ApiKey aWQ6a2V5"},
+		{"已编码的 API Key", []config.Option{config.WithEncodedAPIKey("ZW5jb2RlZA==")}, "ApiKey ZW5jb2RlZA=="},
+		{"Bearer", []config.Option{config.WithBearerToken("token")}, "Bearer token"},
+		// 凭据提供函数同时返回多种凭据时按优先级生效
+		{"API Key 优先", []config.Option{config.WithCredentialsProvider(func(ctx context.Context) (config.Credentials, error) {
+			return config.Credentials{Username: "elastic", Password: "changeme", APIKey: "aWQ6a2V5", BearerToken: "token"}, nil
+		})}, "ApiKey aWQ6a2V5"},
+		{"Bearer 优先于 Basic", []config.Option{config.WithCredentialsProvider(func(ctx context.Context) (config.Credentials, error) {
+			return config.Credentials{Username: "elastic", Password: "changeme", BearerToken: "token"}, nil
+		})}, "Bearer token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var headers []string
+			server := newAuthServer(t, &headers)
+
+			c, err := New(append([]config.Option{config.WithAddresses(server.URL)}, tt.opts...)...)
+			if err != nil {
+				t.Fatalf("创建客户端失败: %v", err)
+			}
+			defer c.Close()
+
+			if _, err := c.Do(context.Background(), http.MethodGet, "/", nil); err != nil {
+				t.Fatalf("请求失败: %v", err)
+			}
+			if len(headers) != 1 || headers[0] != tt.want {
+				t.Fatalf("Authorization 应该为 %q，实际 %v", tt.want, headers)
+			}
+		})
+	}
+
+	t.Logf("✓ 按凭据设置认证头")
+}
+
+func TestClient_CredentialsProvider(t *testing.T) {
+	var headers []string
+	server := newAuthServer(t, &headers)
+
+	// 每次请求（包括重试）重新获取凭据
+	var calls int32
+	provider := func(ctx context.Context) (config.Credentials, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if n == 3 {
+			return config.Credentials{}, errors.New("vault unavailable")
+		}
+		return config.Credentials{BearerToken: fmt.Sprintf("token-%d", n)}, nil
+	}
+
+	c, err := New(
+		config.WithAddresses(server.URL),
+		config.WithCredentialsProvider(provider),
+	)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := c.Do(ctx, http.MethodGet, "/", nil); err != nil {
+			t.Fatalf("请求失败: %v", err)
+		}
+	}
+	if len(headers) != 2 || headers[0] != "Bearer token-1" || headers[1] != "Bearer token-2" {
+		t.Fatalf("每次请求应该使用新的凭据，实际 %v", headers)
+	}
+
+	// 获取凭据失败时不发送请求
+	if _, err := c.Do(ctx, http.MethodGet, "/", nil); err == nil {
+		t.Fatal("获取凭据失败时请求应该失败")
+	}
+	if len(headers) != 2 {
+		t.Fatalf("获取凭据失败时不应该发送请求，实际 %d", len(headers))
+	}
+
+	t.Logf("✓ 动态凭据")
+}
+
+// newTestCert 生成自签名证书和私钥（PEM 格式）
+func newTestCert(t *testing.T, commonName string) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("生成私钥失败: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		IsCA:         true,
+
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("生成证书失败: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("编码私钥失败: %v", err)
+	}
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM
+}
+
+func TestClient_MutualTLS(t *testing.T) {
+	clientCert, clientKey := newTestCert(t, "es-client")
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(clientCert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"cn":"` + r.TLS.PeerCertificates[0].Subject.CommonName + `"}`))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+	caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+	tests := []struct {
+		name    string
+		opts    []config.Option
+		wantErr bool
+	}{
+		{"CA 证书和客户端证书", []config.Option{config.WithCACert(caCert), config.WithClientCert(clientCert, clientKey)}, false},
+		{"缺少客户端证书", []config.Option{config.WithCACert(caCert)}, true},
+		{"缺少 CA 证书", []config.Option{config.WithClientCert(clientCert, clientKey)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := append([]config.Option{config.WithAddresses(server.URL), config.WithRetry(0, 0)}, tt.opts...)
+			c, err := New(opts...)
+			if err != nil {
+				t.Fatalf("创建客户端失败: %v", err)
+			}
+			defer c.Close()
+
+			resp, err := c.Do(context.Background(), http.MethodGet, "/", nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("错误不符合预期: %v", err)
+			}
+			if err == nil && string(resp) != `{"cn":"es-client"}` {
+				t.Fatalf("服务端应该收到客户端证书，实际 %s", resp)
+			}
+		})
+	}
+
+	t.Logf("✓ 双向 TLS")
+}
+
+func TestNewTLSConfig_Invalid(t *testing.T) {
+	cert, key := newTestCert(t, "es-client")
+	_, otherKey := newTestCert(t, "other")
+
+	tests := []struct {
+		name string
+		cfg  *config.Config
+	}{
+		{"无效的 CA 证书", &config.Config{CACert: []byte("not a pem")}},
+		{"证书与私钥不匹配", &config.Config{ClientCert: cert, ClientKey: otherKey}},
+		{"缺少私钥", &config.Config{ClientCert: cert}},
+	}
+	for _, tt := range tests {
+		if _, err := newTLSConfig(tt.cfg); err == nil {
+			t.Errorf("%s: 应该返回错误", tt.name)
+		}
+	}
+
+	if tlsConfig, err := newTLSConfig(&config.Config{ClientCert: cert, ClientKey: key}); err != nil || len(tlsConfig.Certificates) != 1 {
+		t.Fatalf("应该加载客户端证书: %v", err)
+	}
+}
@@ -3,22 +3,34 @@ package client
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
-	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/Kirby980/go-es/config"
 	"github.com/Kirby980/go-es/errors"
+	"github.com/Kirby980/go-es/metrics"
+	"github.com/Kirby980/go-es/tracing"
 )
 
 // Client Elasticsearch 客户端
 type Client struct {
-	config     *config.Config
-	httpClient *http.Client
-	addresses  []string
+	config      *config.Config
+	httpClient  *http.Client
+	pool        *connectionPool
+	retry       config.RetryPolicy
+	roundTrip   config.RoundTripFunc // 中间件链包装后的请求函数
+	metrics     metrics.Metrics      // 未开启指标时为 nil
+	tracer      tracing.Tracer       // 未配置链路追踪时为 nil
+	codec       config.Codec
+	logger      *slog.Logger
+	debugLogger *slog.Logger  // 调用 Debug() 的请求使用的日志
+	done        chan struct{} // 关闭后台任务（定时嗅探）
+	closeOnce   sync.Once
 }
 
 // New 创建新的 ES 客户端
@@ -28,24 +40,70 @@ func New(opts ...config.Option) (*Client, error) {
 		opt(cfg)
 	}
 
+	if cfg.CloudID != "" {
+		address, err := config.ParseCloudID(cfg.CloudID)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Addresses = []string{address}
+	}
+	// 复制地址列表，避免调用方修改影响客户端
+	cfg.Addresses = slices.Clone(cfg.Addresses)
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := newTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
 	// 配置 HTTP Transport
 	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{
-			InsecureSkipVerify: cfg.InsecureSkipVerify,
-		},
+		TLSClientConfig:     tlsConfig,
 		MaxIdleConns:        cfg.MaxIdleConns,
 		MaxIdleConnsPerHost: cfg.MaxIdleConnsPerHost,
 		MaxConnsPerHost:     cfg.MaxConnsPerHost,
 		IdleConnTimeout:     cfg.IdleConnTimeout,
 	}
 
+	retry := cfg.RetryPolicy
+	if retry == nil {
+		retry = config.NewExponentialRetryPolicy(cfg.MaxRetries, cfg.RetryBackoff)
+	}
+
 	client := &Client{
-		config:    cfg,
-		addresses: cfg.Addresses,
+		config: cfg,
+		retry:  retry,
+		tracer: cfg.Tracer,
+		codec:  cfg.Codec,
+		pool:   newConnectionPool(cfg.Addresses, cfg.ResurrectTimeout, cfg.ResurrectMaxDelay),
 		httpClient: &http.Client{
 			Timeout:   cfg.Timeout,
 			Transport: transport,
 		},
+		done: make(chan struct{}),
+	}
+	if client.codec == nil {
+		client.codec = config.JSONCodec{}
+	}
+	client.logger, client.debugLogger = newLoggers(cfg)
+	if cfg.EnableMetrics {
+		client.metrics = cfg.Metrics
+		if client.metrics == nil {
+			client.metrics = metrics.NewInMemory()
+		}
+	}
+	client.roundTrip = buildRoundTrip(newCompressor(cfg).wrap(client.httpClient.Do), cfg.Middlewares, client.loggingMiddleware)
+
+	// 启动时嗅探失败不影响客户端创建，继续使用配置的地址
+	if cfg.SniffOnStart {
+		if err := client.Sniff(context.Background()); err != nil {
+			client.logger.Warn("es sniff on start failed, using configured addresses", slog.Any("error", err))
+		}
+	}
+	if cfg.SniffInterval > 0 {
+		client.startSniffer()
 	}
 
 	return client, nil
@@ -53,135 +111,310 @@ func New(opts ...config.Option) (*Client, error) {
 
 // Close 关闭客户端
 func (c *Client) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
 	return nil
 }
 
-// GetAddress 获取第一个地址
+// GetAddress 获取下一个请求将要使用的节点地址
+// 只读取连接池状态，不影响轮询顺序和死节点复活；执行请求时由客户端选择节点，不需要拼接地址
 func (c *Client) GetAddress() string {
-	if len(c.addresses) > 0 {
-		return c.addresses[0]
+	n, err := c.pool.peek()
+	if err != nil {
+		return ""
 	}
-	return ""
+	return n.url
+}
+
+// Addresses 获取连接池中的全部节点地址
+func (c *Client) Addresses() []string {
+	return c.pool.urls()
+}
+
+// Config 返回客户端配置的只读快照（敏感信息已脱敏），用于诊断
+func (c *Client) Config() config.Config {
+	return c.config.Redacted()
+}
+
+// Codec 获取 JSON 编解码器
+func (c *Client) Codec() config.Codec {
+	return c.codec
+}
+
+// Metrics 获取指标收集器，未开启指标时返回 nil
+func (c *Client) Metrics() metrics.Metrics {
+	return c.metrics
+}
+
+// LiveNodes 获取当前存活的节点数量
+func (c *Client) LiveNodes() int {
+	return c.pool.liveCount()
 }
 
 // DoRequest 执行自定义 HTTP 请求
+// 请求的 scheme 和 host 会被替换为连接池选中的节点（可以只指定路径），失败时自动切换节点重试
 func (c *Client) DoRequest(ctx context.Context, req *http.Request) ([]byte, error) {
-	// 设置认证
-	if c.config.Username != "" {
-		req.SetBasicAuth(c.config.Username, c.config.Password)
+	var body []byte
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("读取请求体失败: %w", err)
+		}
+		body = data
 	}
 
-	// 重试逻辑
-	var resp *http.Response
-	var err error
-	for i := 0; i <= c.config.MaxRetries; i++ {
-		if i > 0 {
-			time.Sleep(c.config.RetryBackoff)
-		}
+	return c.perform(ctx, req.Method, req.URL.RequestURI(), req.Header, body)
+}
 
-		resp, err = c.httpClient.Do(req)
-		if err == nil {
-			break
+// Ping 测试连接
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.perform(ctx, http.MethodGet, "/", nil, nil)
+	if err != nil {
+		if esErr, ok := err.(*errors.ESError); ok {
+			return fmt.Errorf("连接失败，状态码: %d", esErr.StatusCode)
 		}
+		return fmt.Errorf("连接失败: %w", err)
+	}
+	return nil
+}
 
-		if c.config.EnableDebug {
-			fmt.Printf("请求失败，重试 %d/%d: %v\n", i+1, c.config.MaxRetries, err)
+// Do 执行 HTTP 请求
+func (c *Client) Do(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
+	var data []byte
+	if body != nil {
+		var err error
+		data, err = c.codec.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("序列化请求体失败: %w", err)
 		}
 	}
 
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+
+	return c.perform(ctx, method, path, header, data)
+}
+
+// perform 执行请求并读取完整响应体，配置了链路追踪时为整个调用（包括重试）创建一个 span
+func (c *Client) perform(ctx context.Context, method, path string, header http.Header, body []byte) ([]byte, error) {
+	var span tracing.Span
+	if c.tracer != nil {
+		ctx, span = c.startSpan(ctx, method, path, body)
+	}
+
+	respBody, err := c.performBytes(ctx, method, path, header, body)
+	if span != nil {
+		endSpan(span, err)
+	}
+	return respBody, err
+}
+
+// performBytes 执行请求并读取完整响应体
+func (c *Client) performBytes(ctx context.Context, method, path string, header http.Header, body []byte) ([]byte, error) {
+	resp, finish, err := c.execute(ctx, method, path, header, requestBody{data: body})
 	if err != nil {
-		return nil, fmt.Errorf("请求失败: %w", err)
+		var respBody []byte
+		if esErr, ok := err.(*errors.ESError); ok {
+			respBody = esErr.RawBody
+		}
+		return respBody, err
 	}
-	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	finish(len(respBody), err)
 	if err != nil {
 		return nil, fmt.Errorf("读取响应失败: %w", err)
 	}
-
-	if resp.StatusCode >= 400 {
-		return respBody, errors.ParseESError(resp.StatusCode, respBody)
-	}
-
 	return respBody, nil
 }
 
-// Ping 测试连接
-func (c *Client) Ping(ctx context.Context) error {
-	req, err := http.NewRequestWithContext(ctx, "GET", c.addresses[0], nil)
-	if err != nil {
-		return fmt.Errorf("创建请求失败: %w", err)
-	}
+// requestBody 请求体
+// data 和 open 每次尝试都重新创建读取器，可以安全重放；stream 只能发送一次，不会自动重试
+type requestBody struct {
+	data   []byte
+	open   func() (io.ReadCloser, error) // 内存中的请求体（如 http.Request.GetBody），每次尝试重新打开，不复制数据
+	length int64                         // open 返回的请求体长度
+	stream *countingReader
+}
 
-	if c.config.Username != "" {
-		req.SetBasicAuth(c.config.Username, c.config.Password)
+// newRequest 创建本次尝试使用的请求
+func (b requestBody) newRequest(ctx context.Context, method, url string) (*http.Request, error) {
+	if b.open == nil {
+		var body io.Reader
+		switch {
+		case b.stream != nil:
+			body = b.stream
+		case b.data != nil:
+			body = bytes.NewReader(b.data)
+		}
+		return http.NewRequestWithContext(ctx, method, url, body)
 	}
 
-	resp, err := c.httpClient.Do(req)
+	body, err := b.open()
 	if err != nil {
-		return fmt.Errorf("连接失败: %w", err)
+		return nil, fmt.Errorf("读取请求体失败: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		body.Close()
+		return nil, err
 	}
-	defer resp.Body.Close()
+	req.ContentLength = b.length
+	req.GetBody = b.open
+	return req, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("连接失败，状态码: %d", resp.StatusCode)
+// head 返回请求体开头最多 n 字节（用于链路追踪），内存中的请求体直接返回
+func (b requestBody) head(n int) []byte {
+	if b.open == nil {
+		return b.data
+	}
+	body, err := b.open()
+	if err != nil {
+		return nil
 	}
+	defer body.Close()
+	data, _ := io.ReadAll(io.LimitReader(body, int64(n)))
+	return data
+}
 
-	return nil
+// size 返回已发送的请求体大小
+func (b requestBody) size() int {
+	switch {
+	case b.stream != nil:
+		return int(b.stream.n)
+	case b.open != nil:
+		return int(b.length)
+	default:
+		return len(b.data)
+	}
 }
 
-// Do 执行 HTTP 请求
-func (c *Client) Do(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
-	var reqBody io.Reader
-	if body != nil {
-		data, err := json.Marshal(body)
+// execute 通过连接池执行请求，返回状态码小于 400 的响应，响应体由调用方读取并关闭
+// 调用方读取完响应体后必须调用 finish 记录指标
+// 连接失败或节点返回 502/503/504 时将节点标记为死亡；是否重试由重试策略决定，重试会切换到下一个存活节点
+func (c *Client) execute(ctx context.Context, method, path string, header http.Header, body requestBody) (*http.Response, func(received int, err error), error) {
+	retryable := !retryDisabled(ctx) && body.stream == nil
+	endpoint := method + " " + endpointOf(path)
+
+	for attempt := 1; ; attempt++ {
+		n, err := c.pool.next()
 		if err != nil {
-			return nil, fmt.Errorf("序列化请求体失败: %w", err)
+			return nil, nil, err
 		}
-		reqBody = bytes.NewReader(data)
-	}
 
-	url := c.addresses[0] + path
-	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("创建请求失败: %w", err)
-	}
+		// 每次尝试都重新创建请求，保证请求体可以重放
+		req, err := body.newRequest(ctx, method, n.url+path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("创建请求失败: %w", err)
+		}
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		if err := c.authorize(req); err != nil {
+			return nil, nil, err
+		}
+		traceAttempt(req, attempt)
 
-	req.Header.Set("Content-Type", "application/json")
-	if c.config.Username != "" {
-		req.SetBasicAuth(c.config.Username, c.config.Password)
-	}
+		start := time.Now()
+		resp, err := c.roundTrip(req)
+		if err != nil {
+			c.observeRequest(endpoint, 0, time.Since(start), body.size(), 0)
+
+			// 调用方取消或超时，不是节点的问题，直接返回
+			if ctx.Err() != nil {
+				c.observeError(endpoint, "context_canceled")
+				return nil, nil, fmt.Errorf("请求失败: %w", err)
+			}
+			c.pool.markDead(n)
+
+			if !retryable || !c.retry.ShouldRetry(attempt, nil, err) {
+				c.observeError(endpoint, "transport_error")
+				c.logger.LogAttrs(ctx, slog.LevelError, "es request error",
+					slog.String("method", method), slog.String("path", path), slog.String("node", n.url),
+					slog.Int("attempts", attempt), slog.Any("error", err))
+				return nil, nil, fmt.Errorf("请求失败: %w", err)
+			}
+			backoff := c.retry.Backoff(attempt, nil)
+			c.logger.LogAttrs(ctx, slog.LevelWarn, "es request retry",
+				slog.String("method", method), slog.String("path", path), slog.String("node", n.url),
+				slog.Int("attempt", attempt), slog.Duration("backoff", backoff), slog.Any("error", err))
+			c.observeRetry(endpoint)
+			if err := sleepContext(ctx, backoff); err != nil {
+				return nil, nil, fmt.Errorf("请求失败: %w", err)
+			}
+			continue
+		}
 
-	// 重试逻辑
-	var resp *http.Response
-	for i := 0; i <= c.config.MaxRetries; i++ {
-		if i > 0 {
-			time.Sleep(c.config.RetryBackoff)
+		traceStatus(ctx, resp.StatusCode)
+		if isNodeUnavailable(resp.StatusCode) {
+			c.pool.markDead(n)
+		} else {
+			c.pool.markAlive(n)
 		}
 
-		resp, err = c.httpClient.Do(req)
-		if err == nil {
-			break
+		if resp.StatusCode < 400 {
+			finish := func(received int, err error) {
+				c.observeRequest(endpoint, resp.StatusCode, time.Since(start), body.size(), received)
+				if err != nil {
+					c.observeError(endpoint, "read_error")
+				}
+			}
+			return resp, finish, nil
 		}
 
-		if c.config.EnableDebug {
-			fmt.Printf("请求失败，重试 %d/%d: %v\n", i+1, c.config.MaxRetries, err)
+		// 错误响应体较小，直接读取用于解析错误
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		c.observeRequest(endpoint, resp.StatusCode, time.Since(start), body.size(), len(respBody))
+		if err != nil {
+			c.observeError(endpoint, "read_error")
+			return nil, nil, fmt.Errorf("读取响应失败: %w", err)
 		}
-	}
 
-	if err != nil {
-		return nil, fmt.Errorf("请求失败: %w", err)
+		esErr := errors.ParseESError(resp.StatusCode, respBody)
+		if !retryable || !c.retry.ShouldRetry(attempt, resp, nil) {
+			c.observeError(endpoint, errorType(esErr))
+			// 4xx 属于调用方错误，只在请求结束事件中体现；5xx 记录为错误
+			if resp.StatusCode >= 500 {
+				c.logger.LogAttrs(ctx, slog.LevelError, "es request error",
+					slog.String("method", method), slog.String("path", path), slog.String("node", n.url),
+					slog.Int("attempts", attempt), slog.Int("status", resp.StatusCode), slog.String("error_type", esErr.Type))
+			}
+			return nil, nil, esErr
+		}
+		backoff := c.retry.Backoff(attempt, resp)
+		c.logger.LogAttrs(ctx, slog.LevelWarn, "es request retry",
+			slog.String("method", method), slog.String("path", path), slog.String("node", n.url),
+			slog.Int("attempt", attempt), slog.Duration("backoff", backoff), slog.Int("status", resp.StatusCode))
+		c.observeRetry(endpoint)
+		if err := sleepContext(ctx, backoff); err != nil {
+			return nil, nil, esErr
+		}
 	}
-	defer resp.Body.Close()
+}
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("读取响应失败: %w", err)
+// sleepContext 等待指定时间，context 结束时提前返回
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
 	}
-
-	if resp.StatusCode >= 400 {
-		return respBody, errors.ParseESError(resp.StatusCode, respBody)
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
 	}
+}
 
-	return respBody, nil
+// isNodeUnavailable 判断状态码是否表示节点暂时不可用
+func isNodeUnavailable(statusCode int) bool {
+	return statusCode == http.StatusBadGateway ||
+		statusCode == http.StatusServiceUnavailable ||
+		statusCode == http.StatusGatewayTimeout
 }
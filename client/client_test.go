@@ -0,0 +1,201 @@
+package client
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Kirby980/go-es/config"
+)
+
+// newCountingServer 创建统计请求次数的测试服务，响应 200 和 {}
+func newCountingServer(t *testing.T, count *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(count, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_GetAddressDoesNotAffectRoundRobin(t *testing.T) {
+	var countA, countB int32
+	a := newCountingServer(t, &countA)
+	b := newCountingServer(t, &countB)
+
+	c, err := New(config.WithAddresses(a.URL, b.URL))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	// 每次请求前调用 GetAddress（与拼接完整地址的调用方相同），请求仍然轮询两个节点
+	for i := 0; i < 4; i++ {
+		if addr := c.GetAddress(); addr == "" {
+			t.Fatal("GetAddress 不应该返回空地址")
+		}
+		if _, err := c.Do(ctx, http.MethodGet, "/", nil); err != nil {
+			t.Fatalf("请求失败: %v", err)
+		}
+	}
+
+	if countA != 2 || countB != 2 {
+		t.Fatalf("两个节点应该各收到 2 个请求，实际 %d、%d", countA, countB)
+	}
+
+	t.Logf("✓ GetAddress 不影响轮询")
+}
+
+func TestClient_DoRequestRelativePath(t *testing.T) {
+	var count int32
+	server := newCountingServer(t, &count)
+
+	c, err := New(config.WithAddresses(server.URL))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer c.Close()
+
+	// 只指定路径的请求由客户端选择节点
+	req, err := http.NewRequest(http.MethodPost, "/_msearch", nil)
+	if err != nil {
+		t.Fatalf("创建请求失败: %v", err)
+	}
+	if _, err := c.DoRequest(context.Background(), req); err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("应该收到 1 个请求，实际 %d", count)
+	}
+
+	t.Logf("✓ 相对路径请求成功")
+}
+
+// closedServerURL 返回已经关闭的服务地址，连接会被拒绝
+func closedServerURL(t *testing.T) string {
+	t.Helper()
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	return server.URL
+}
+
+func TestClient_FailoverOnConnectionError(t *testing.T) {
+	var count int32
+	live := newCountingServer(t, &count)
+
+	c, err := New(
+		config.WithAddresses(closedServerURL(t), live.URL),
+		config.WithRetry(1, time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer c.Close()
+
+	// 第一个节点连接失败，切换到第二个节点重试
+	if _, err := c.Do(context.Background(), http.MethodGet, "/", nil); err != nil {
+		t.Fatalf("应该切换节点后成功: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("存活节点应该收到 1 个请求，实际 %d", count)
+	}
+	if c.LiveNodes() != 1 {
+		t.Fatalf("连接失败的节点应该被标记死亡，存活节点 %d", c.LiveNodes())
+	}
+
+	// 死节点在复活时间之前不再被选择
+	for i := 0; i < 3; i++ {
+		if _, err := c.Do(context.Background(), http.MethodGet, "/", nil); err != nil {
+			t.Fatalf("请求失败: %v", err)
+		}
+	}
+	if count != 4 {
+		t.Fatalf("后续请求都应该发往存活节点，实际 %d", count)
+	}
+
+	t.Logf("✓ 连接失败时切换节点")
+}
+
+func TestClient_FailoverOnUnavailable(t *testing.T) {
+	var unavailable, count int32
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&unavailable, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	live := newCountingServer(t, &count)
+
+	c, err := New(
+		config.WithAddresses(down.URL, live.URL),
+		config.WithRetry(1, time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer c.Close()
+
+	if _, err := c.Do(context.Background(), http.MethodGet, "/", nil); err != nil {
+		t.Fatalf("应该切换节点后成功: %v", err)
+	}
+	if unavailable != 1 || count != 1 {
+		t.Fatalf("两个节点应该各收到 1 个请求，实际 %d、%d", unavailable, count)
+	}
+	if c.LiveNodes() != 1 {
+		t.Fatalf("返回 503 的节点应该被标记死亡，存活节点 %d", c.LiveNodes())
+	}
+
+	t.Logf("✓ 节点返回 503 时切换节点")
+}
+
+func TestClient_CloudID(t *testing.T) {
+	cloudID := "my-deployment:" + base64.StdEncoding.EncodeToString([]byte("us-east-1.aws.found.io:9243$es123$kb456"))
+
+	// Cloud ID 覆盖配置的地址
+	c, err := New(config.WithAddresses("http://localhost:9200"), config.WithCloudID(cloudID))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer c.Close()
+	if got := c.Addresses(); len(got) != 1 || got[0] != "https://es123.us-east-1.aws.found.io:9243" {
+		t.Fatalf("应该使用 Cloud ID 解析出的地址，实际 %v", got)
+	}
+
+	if _, err := New(config.WithCloudID("invalid")); err == nil {
+		t.Fatal("无效的 Cloud ID 应该返回错误")
+	}
+
+	t.Logf("✓ 使用 Cloud ID 连接")
+}
+
+func TestClient_Config(t *testing.T) {
+	if _, err := New(config.WithAddresses("localhost:9200")); err == nil {
+		t.Fatal("无效的配置应该返回错误")
+	}
+
+	addresses := []string{"http://localhost:9200"}
+	c, err := New(config.WithAddresses(addresses...), config.WithAuth("elastic", "changeme"))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer c.Close()
+
+	// 返回的配置已脱敏，修改后不影响客户端
+	cfg := c.Config()
+	if cfg.Password != "[REDACTED]" {
+		t.Fatalf("密码应该被隐藏，实际 %s", cfg.Password)
+	}
+	cfg.Addresses[0] = "http://changed:9200"
+	addresses[0] = "http://changed:9200"
+	if got := c.Config().Addresses[0]; got != "http://localhost:9200" {
+		t.Fatalf("修改返回的配置或传入的地址不应该影响客户端，实际 %s", got)
+	}
+
+	t.Logf("✓ 校验配置并返回只读副本")
+}
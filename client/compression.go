@@ -0,0 +1,172 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/Kirby980/go-es/config"
+)
+
+// compressor gzip 压缩请求体并解压响应体
+type compressor struct {
+	level     int
+	threshold int
+	writers   sync.Pool
+}
+
+// newCompressor 创建压缩器，未开启压缩时返回 nil
+func newCompressor(cfg *config.Config) *compressor {
+	if !cfg.CompressRequestBody {
+		return nil
+	}
+	return &compressor{
+		level:     cfg.CompressionLevel,
+		threshold: cfg.CompressionThreshold,
+	}
+}
+
+// wrap 包装在 HTTP 客户端外层（中间件链和日志之内），中间件和日志看到的都是未压缩的内容
+func (c *compressor) wrap(next config.RoundTripFunc) config.RoundTripFunc {
+	if c == nil {
+		return next
+	}
+	return func(req *http.Request) (*http.Response, error) {
+		req, err := c.compressRequest(req)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := next(req)
+		if err != nil {
+			return nil, err
+		}
+		if err := decompressResponse(resp); err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		return resp, nil
+	}
+}
+
+// compressRequest 请求体超过阈值时进行 gzip 压缩，并声明接受 gzip 响应
+func (c *compressor) compressRequest(req *http.Request) (*http.Request, error) {
+	req = req.Clone(req.Context())
+	// 显式设置 Accept-Encoding 后，http.Transport 不再自动解压，由 decompressResponse 处理
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	if req.Body == nil || req.Body == http.NoBody || req.Header.Get("Content-Encoding") != "" {
+		return req, nil
+	}
+
+	// 流式请求体长度未知，边读边压缩
+	if req.GetBody == nil {
+		req.Body = c.gzipStream(req.Body)
+		req.ContentLength = -1
+		req.Header.Set("Content-Encoding", "gzip")
+		return req, nil
+	}
+
+	// 长度已知且低于阈值时不压缩，请求体保持不变
+	if req.ContentLength >= 0 && req.ContentLength < int64(c.threshold) {
+		return req, nil
+	}
+
+	// 直接从请求体读取并压缩，不复制未压缩的数据
+	body, err := c.gzip(req.Body, req.ContentLength)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("压缩请求体失败: %w", err)
+	}
+
+	req.Header.Set("Content-Encoding", "gzip")
+	req.ContentLength = int64(len(body))
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return req, nil
+}
+
+// gzip 压缩读取器中的数据，复用 gzip.Writer；size 为未压缩数据的长度，用于预分配缓冲区
+func (c *compressor) gzip(r io.Reader, size int64) ([]byte, error) {
+	var buf bytes.Buffer
+	if size > 0 {
+		buf.Grow(int(size / 4))
+	}
+
+	w, ok := c.writers.Get().(*gzip.Writer)
+	if ok {
+		w.Reset(&buf)
+	} else {
+		var err error
+		if w, err = gzip.NewWriterLevel(&buf, c.level); err != nil {
+			return nil, err
+		}
+	}
+	defer c.writers.Put(w)
+
+	if _, err := io.Copy(w, r); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// gzipStream 通过管道边读边压缩流式请求体
+func (c *compressor) gzipStream(body io.ReadCloser) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		defer body.Close()
+
+		w, err := gzip.NewWriterLevel(pw, c.level)
+		if err == nil {
+			_, err = io.Copy(w, body)
+			if closeErr := w.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+// decompressResponse 解压 gzip 响应体
+func decompressResponse(resp *http.Response) error {
+	if resp.Header.Get("Content-Encoding") != "gzip" {
+		return nil
+	}
+
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		// 空响应体（如 HEAD 请求）没有 gzip 头
+		if err == io.EOF {
+			return nil
+		}
+		return fmt.Errorf("解压响应失败: %w", err)
+	}
+
+	resp.Body = &gzipReadCloser{Reader: gz, body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
+
+// gzipReadCloser 关闭时同时关闭 gzip 读取器和原始响应体
+type gzipReadCloser struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+// Close 关闭读取器
+func (r *gzipReadCloser) Close() error {
+	r.Reader.Close()
+	return r.body.Close()
+}
@@ -0,0 +1,259 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Kirby980/go-es/config"
+)
+
+// gzipRequest 测试服务收到的请求
+type gzipRequest struct {
+	encoding       string
+	acceptEncoding string
+	body           string
+}
+
+// newGzipServer 创建测试服务：解压 gzip 请求体并记录，客户端接受 gzip 时压缩响应
+// 前 failures 次请求返回 503
+func newGzipServer(t *testing.T, response string, failures int, requests *[]gzipRequest) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reader io.Reader = r.Body
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(r.Body)
+			if err != nil {
+				t.Errorf("解压请求体失败: %v", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			reader = gz
+		}
+		body, _ := io.ReadAll(reader)
+
+		mu.Lock()
+		*requests = append(*requests, gzipRequest{
+			encoding:       r.Header.Get("Content-Encoding"),
+			acceptEncoding: r.Header.Get("Accept-Encoding"),
+			body:           string(body),
+		})
+		attempt := len(*requests)
+		mu.Unlock()
+
+		if attempt <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("Accept-Encoding") == "gzip" {
+			w.Header().Set("Content-Encoding", "gzip")
+			gz := gzip.NewWriter(w)
+			gz.Write([]byte(response))
+			gz.Close()
+			return
+		}
+		w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_Compression(t *testing.T) {
+	large := map[string]interface{}{"text": strings.Repeat("elasticsearch ", 200)}
+	small := map[string]interface{}{"text": "es"}
+
+	tests := []struct {
+		name         string
+		opts         []config.Option
+		body         interface{}
+		wantEncoding string
+		wantAccept   string
+	}{
+		{"超过阈值时压缩", []config.Option{config.WithCompression(gzip.BestSpeed)}, large, "gzip", "gzip"},
+		{"低于阈值时不压缩", []config.Option{config.WithCompression(gzip.BestSpeed)}, small, "", "gzip"},
+		{"自定义阈值", []config.Option{config.WithCompression(gzip.DefaultCompression), config.WithCompressionThreshold(1)}, small, "gzip", "gzip"},
+		{"未开启压缩", nil, large, "", "gzip"}, // http.Transport 自动添加 Accept-Encoding
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []gzipRequest
+			server := newGzipServer(t, `{"result":"created"}`, 0, &requests)
+
+			c, err := New(append([]config.Option{config.WithAddresses(server.URL)}, tt.opts...)...)
+			if err != nil {
+				t.Fatalf("创建客户端失败: %v", err)
+			}
+			defer c.Close()
+
+			resp, err := c.Do(context.Background(), http.MethodPost, "/test/_doc", tt.body)
+			if err != nil {
+				t.Fatalf("请求失败: %v", err)
+			}
+			if string(resp) != `{"result":"created"}` {
+				t.Fatalf("响应体应该被解压，实际 %q", resp)
+			}
+			if len(requests) != 1 {
+				t.Fatalf("应该发送 1 次请求，实际 %d", len(requests))
+			}
+			req := requests[0]
+			if req.encoding != tt.wantEncoding || req.acceptEncoding != tt.wantAccept {
+				t.Fatalf("Content-Encoding/Accept-Encoding 应该为 %q/%q，实际 %q/%q", tt.wantEncoding, tt.wantAccept, req.encoding, req.acceptEncoding)
+			}
+			if !strings.HasPrefix(req.body, `{"text":"`) {
+				t.Fatalf("服务端应该收到完整的请求体，实际 %q", req.body)
+			}
+		})
+	}
+
+	t.Logf("✓ 按阈值压缩请求体并解压响应")
+}
+
+func TestClient_CompressionRetry(t *testing.T) {
+	var requests []gzipRequest
+	server := newGzipServer(t, `{}`, 1, &requests)
+
+	c, err := New(
+		config.WithAddresses(server.URL),
+		config.WithCompression(gzip.BestSpeed),
+		config.WithCompressionThreshold(1),
+		config.WithRetry(1, time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer c.Close()
+
+	if _, err := c.Do(context.Background(), http.MethodPost, "/test/_doc", map[string]interface{}{"n": 1}); err != nil {
+		t.Fatalf("请求应该在重试后成功: %v", err)
+	}
+	// 重试时重新发送相同的压缩请求体
+	if len(requests) != 2 || requests[0] != requests[1] || requests[1].encoding != "gzip" || requests[1].body != `{"n":1}` {
+		t.Fatalf("重试应该发送相同的压缩请求体: %+v", requests)
+	}
+
+	t.Logf("✓ 压缩的请求体可以重试")
+}
+
+func TestClient_CompressionStream(t *testing.T) {
+	var requests []gzipRequest
+	server := newGzipServer(t, `{"took":1}`, 0, &requests)
+
+	c, err := New(config.WithAddresses(server.URL), config.WithCompression(gzip.BestSpeed))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer c.Close()
+
+	// 长度未知的流式请求体边读边压缩
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write([]byte("{\"index\":{}}\n{\"n\":1}\n"))
+		pw.Close()
+	}()
+	body, err := c.Stream(context.Background(), http.MethodPost, "/_bulk", pr)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	data, err := io.ReadAll(body)
+	body.Close()
+	if err != nil || string(data) != `{"took":1}` {
+		t.Fatalf("流式响应应该被解压，实际 %q（%v）", data, err)
+	}
+	if len(requests) != 1 || requests[0].encoding != "gzip" || requests[0].body != "{\"index\":{}}\n{\"n\":1}\n" {
+		t.Fatalf("流式请求体应该被压缩: %+v", requests)
+	}
+
+	t.Logf("✓ 流式请求体压缩")
+}
+
+func TestDecompressResponse(t *testing.T) {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte(`{"ok":true}`))
+	gz.Close()
+
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+		want     string
+		wantErr  bool
+	}{
+		{"gzip", "gzip", compressed.Bytes(), `{"ok":true}`, false},
+		{"未压缩", "", []byte(`{"ok":true}`), `{"ok":true}`, false},
+		{"空响应体", "gzip", nil, "", false},
+		{"无效的 gzip", "gzip", []byte("not gzip"), "", true},
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{Header: http.Header{}, Body: io.NopCloser(bytes.NewReader(tt.body))}
+		if tt.encoding != "" {
+			resp.Header.Set("Content-Encoding", tt.encoding)
+		}
+		err := decompressResponse(resp)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: 错误不符合预期: %v", tt.name, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(data) != tt.want {
+			t.Errorf("%s: 响应体 = %q，期望 %q", tt.name, data, tt.want)
+		}
+	}
+}
+
+func TestClient_CompressionMemoryBody(t *testing.T) {
+	const size = 8 << 20
+	payload := bytes.Repeat([]byte("{\"index\":{}}\n{\"n\":1}\n"), size/21)
+
+	var received int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received, _ = io.Copy(io.Discard, gz)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	c, err := New(config.WithAddresses(server.URL), config.WithCompression(gzip.BestSpeed))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer c.Close()
+
+	var body io.ReadCloser
+	allocated := allocatedBytes(func() {
+		if body, err = c.Stream(context.Background(), http.MethodPost, "/_bulk", bytes.NewReader(payload)); err == nil {
+			body.Close()
+		}
+	})
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	if received != int64(len(payload)) {
+		t.Fatalf("服务端应该收到完整的请求体，实际 %d 字节", received)
+	}
+	// 直接从内存中的请求体压缩，只分配 gzip 状态和压缩缓冲区（按请求体的 1/4 预分配），
+	// 压缩前复制未压缩的请求体时会超过请求体大小
+	if allocated > size {
+		t.Fatalf("压缩前不应该复制请求体，%d 字节的请求体分配了 %d 字节", len(payload), allocated)
+	}
+
+	t.Logf("✓ 压缩内存中的请求体不复制")
+}
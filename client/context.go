@@ -0,0 +1,30 @@
+package client
+
+import "context"
+
+type retryDisabledKey struct{}
+
+// WithoutRetry 返回禁用重试的 context
+// 用于非幂等操作（如自动生成 ID 的文档写入），避免请求已被服务端处理但响应丢失时重复执行
+func WithoutRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, retryDisabledKey{}, true)
+}
+
+// retryDisabled 判断 context 是否禁用了重试
+func retryDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(retryDisabledKey{}).(bool)
+	return disabled
+}
+
+type debugKey struct{}
+
+// WithDebug 返回开启调试输出的 context，该 context 发出的请求会以 Info 级别记录请求和响应体
+func WithDebug(ctx context.Context) context.Context {
+	return context.WithValue(ctx, debugKey{}, true)
+}
+
+// debugEnabled 判断 context 是否开启了调试输出
+func debugEnabled(ctx context.Context) bool {
+	enabled, _ := ctx.Value(debugKey{}).(bool)
+	return enabled
+}
@@ -0,0 +1,74 @@
+package client
+
+import "strings"
+
+// nestedEndpoints 需要保留第二级路径的接口
+var nestedEndpoints = map[string]bool{
+	"_cluster": true,
+	"_search":  true,
+	"_cat":     true,
+	"_remote":  true,
+}
+
+// nodeIDPlaceholder 替换 _nodes 路径中的节点 ID，避免指标标签的取值无限增长
+const nodeIDPlaceholder = "{node_id}"
+
+// nodesAPIs _nodes 后可以直接跟随的接口和信息类别，其余第二级路径视为节点 ID（包括 _local、_master 和逗号分隔的列表）
+var nodesAPIs = map[string]bool{
+	"stats":                  true,
+	"usage":                  true,
+	"hot_threads":            true,
+	"reload_secure_settings": true,
+	"settings":               true,
+	"os":                     true,
+	"process":                true,
+	"jvm":                    true,
+	"thread_pool":            true,
+	"transport":              true,
+	"http":                   true,
+	"plugins":                true,
+	"ingest":                 true,
+	"indices":                true,
+	"aggregations":           true,
+}
+
+// endpointOf 将请求路径归一化为接口名称，用于指标和链路追踪
+// 例如 "/products/_doc/1" -> "_doc"，"/_cluster/health" -> "_cluster/health"，"/products" -> "index"，
+// "/_nodes/abc123/stats" -> "_nodes/{node_id}/stats"
+func endpointOf(path string) string {
+	if idx := strings.IndexByte(path, '?'); idx >= 0 {
+		path = path[:idx]
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) == 1 && segments[0] == "" {
+		return "root"
+	}
+
+	for i, seg := range segments {
+		if !strings.HasPrefix(seg, "_") {
+			continue
+		}
+		if seg == "_nodes" {
+			return nodesEndpoint(segments[i+1:])
+		}
+		if nestedEndpoints[seg] && i+1 < len(segments) {
+			return seg + "/" + segments[i+1]
+		}
+		return seg
+	}
+	return "index"
+}
+
+// nodesEndpoint 归一化 _nodes 之后的路径，节点 ID 替换为占位符
+func nodesEndpoint(rest []string) string {
+	if len(rest) == 0 {
+		return "_nodes"
+	}
+	if nodesAPIs[rest[0]] {
+		return "_nodes/" + rest[0]
+	}
+	if len(rest) > 1 {
+		return "_nodes/" + nodeIDPlaceholder + "/" + rest[1]
+	}
+	return "_nodes/" + nodeIDPlaceholder
+}
@@ -0,0 +1,36 @@
+package client
+
+import "testing"
+
+func TestEndpointOf(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/", "root"},
+		{"", "root"},
+		{"/products", "index"},
+		{"/products/_doc/1", "_doc"},
+		{"/products/_doc/1?refresh=true", "_doc"},
+		{"/products/_search", "_search"},
+		{"/_search/scroll", "_search/scroll"},
+		{"/_cluster/health", "_cluster/health"},
+		{"/_cluster/health/products", "_cluster/health"},
+		{"/_cat/indices/products", "_cat/indices"},
+		{"/_bulk", "_bulk"},
+		{"/_tasks/node:123", "_tasks"},
+		{"/_nodes", "_nodes"},
+		{"/_nodes/http", "_nodes/http"},
+		{"/_nodes/stats/jvm", "_nodes/stats"},
+		{"/_nodes/abc123", "_nodes/{node_id}"},
+		{"/_nodes/abc123/stats", "_nodes/{node_id}/stats"},
+		{"/_nodes/abc123,def456/stats/jvm", "_nodes/{node_id}/stats"},
+		{"/_nodes/_local/hot_threads", "_nodes/{node_id}/hot_threads"},
+	}
+
+	for _, tt := range tests {
+		if got := endpointOf(tt.path); got != tt.want {
+			t.Errorf("endpointOf(%q) = %q，期望 %q", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/Kirby980/go-es/config"
+)
+
+// maxLoggedBodySize 日志中请求/响应体的最大长度，超出部分被截断
+const maxLoggedBodySize = 16 * 1024
+
+// redactedValue 脱敏后的字段值
+const redactedValue = "[REDACTED]"
+
+// redactedTruncatedBody 配置了脱敏但请求/响应体被截断且无法脱敏时的占位内容
+const redactedTruncatedBody = "[REDACTED: body exceeds 16KB]"
+
+// newLoggers 根据配置创建日志
+// logger 用于常规事件；debugLogger 用于调用 Debug() 的请求，未配置日志时输出到标准输出
+func newLoggers(cfg *config.Config) (logger, debugLogger *slog.Logger) {
+	stdout := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	switch {
+	case cfg.Logger != nil:
+		return cfg.Logger, cfg.Logger
+	case cfg.EnableDebug:
+		return stdout, stdout
+	default:
+		return slog.New(slog.DiscardHandler), stdout
+	}
+}
+
+// requestLogger 返回请求使用的日志和请求事件级别
+// 调用 Debug() 的请求以 Info 级别输出并附带请求/响应体，保证在默认日志级别下可见
+func (c *Client) requestLogger(ctx context.Context) (*slog.Logger, slog.Level) {
+	if debugEnabled(ctx) {
+		return c.debugLogger, slog.LevelInfo
+	}
+	return c.logger, slog.LevelDebug
+}
+
+// loggingMiddleware 日志中间件，记录每次请求尝试的开始、结束和慢请求
+func (c *Client) loggingMiddleware(next config.RoundTripFunc) config.RoundTripFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		ctx := req.Context()
+		logger, level := c.requestLogger(ctx)
+		enabled := logger.Enabled(ctx, level)
+
+		attrs := []slog.Attr{
+			slog.String("method", req.Method),
+			slog.String("path", req.URL.Path),
+			slog.String("node", req.URL.Scheme+"://"+req.URL.Host),
+		}
+
+		if enabled {
+			startAttrs := attrs
+			if body := c.requestBodyForLog(req); body != "" {
+				startAttrs = append(startAttrs[:len(startAttrs):len(startAttrs)], slog.String("request_body", body))
+			}
+			logger.LogAttrs(ctx, level, "es request start", startAttrs...)
+		}
+
+		start := time.Now()
+		resp, err := next(req)
+		duration := time.Since(start)
+
+		// 失败的尝试由 execute 记录为重试或错误（带尝试次数），这里只记录请求结束事件
+		if err != nil {
+			if enabled {
+				logger.LogAttrs(ctx, level, "es request end",
+					append(attrs, slog.Duration("duration", duration), slog.Any("error", err))...)
+			}
+			return resp, err
+		}
+
+		attrs = append(attrs, slog.Int("status", resp.StatusCode), slog.Duration("duration", duration))
+		if enabled {
+			endAttrs := attrs
+			if body := c.responseBodyForLog(resp); body != "" {
+				endAttrs = append(endAttrs[:len(endAttrs):len(endAttrs)], slog.String("response_body", body))
+			}
+			logger.LogAttrs(ctx, level, "es request end", endAttrs...)
+		}
+
+		if c.config.SlowRequestThreshold > 0 && duration >= c.config.SlowRequestThreshold {
+			logger.LogAttrs(ctx, slog.LevelWarn, "es slow request",
+				append(attrs, slog.Duration("threshold", c.config.SlowRequestThreshold))...)
+		}
+
+		return resp, nil
+	}
+}
+
+// requestBodyForLog 读取请求体用于日志（不影响实际发送）
+func (c *Client) requestBodyForLog(req *http.Request) string {
+	if req.GetBody == nil {
+		return ""
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+	data, err := io.ReadAll(io.LimitReader(body, maxLoggedBodySize+1))
+	if err != nil {
+		return ""
+	}
+	return c.formatBodyForLog(data)
+}
+
+// responseBodyForLog 读取响应体开头部分用于日志，读取的内容重新放回响应，不影响流式读取
+func (c *Client) responseBodyForLog(resp *http.Response) string {
+	if resp.Body == nil {
+		return ""
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxLoggedBodySize+1))
+	resp.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(data), resp.Body), Closer: resp.Body}
+	if err != nil {
+		return ""
+	}
+	return c.formatBodyForLog(data)
+}
+
+// readCloser 组合读取器和关闭器
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// formatBodyForLog 对请求/响应体脱敏并截断
+func (c *Client) formatBodyForLog(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+
+	truncated := false
+	if len(data) > maxLoggedBodySize {
+		data = data[:maxLoggedBodySize]
+		truncated = true
+	}
+
+	if len(c.config.LogRedactFields) > 0 {
+		// 截断位置之后的内容无法解析，可能是敏感字段的一部分：只保留完整的行，没有完整的行时省略请求/响应体
+		if truncated {
+			i := bytes.LastIndexByte(data, '\n')
+			if i < 0 {
+				return redactedTruncatedBody
+			}
+			data = data[:i]
+		}
+		data = redactBody(data, c.config.LogRedactFields)
+	}
+
+	if truncated {
+		return string(data) + "...(truncated)"
+	}
+	return string(data)
+}
+
+// redactBody 脱敏 JSON 或 NDJSON 中指定字段的值
+func redactBody(data []byte, fields []string) []byte {
+	redact := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		redact[f] = true
+	}
+
+	lines := bytes.Split(bytes.TrimRight(data, "\n"), []byte("\n"))
+	out := make([][]byte, 0, len(lines))
+	for _, line := range lines {
+		var v interface{}
+		if err := json.Unmarshal(line, &v); err != nil {
+			out = append(out, line)
+			continue
+		}
+		redacted, err := json.Marshal(redactValue(v, redact))
+		if err != nil {
+			out = append(out, line)
+			continue
+		}
+		out = append(out, redacted)
+	}
+	return bytes.Join(out, []byte("\n"))
+}
+
+// redactValue 递归替换需要脱敏的字段
+func redactValue(v interface{}, redact map[string]bool) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			if redact[k] {
+				val[k] = redactedValue
+			} else {
+				val[k] = redactValue(item, redact)
+			}
+		}
+		return val
+	case []interface{}:
+		for i, item := range val {
+			val[i] = redactValue(item, redact)
+		}
+		return val
+	default:
+		return v
+	}
+}
@@ -0,0 +1,219 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Kirby980/go-es/config"
+)
+
+// logRecords 解析 JSON 日志，返回每条记录
+func logRecords(t *testing.T, logs *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	dec := json.NewDecoder(logs)
+	for dec.More() {
+		var record map[string]interface{}
+		if err := dec.Decode(&record); err != nil {
+			t.Fatalf("解析日志失败: %v", err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLogging_FailedAttemptLoggedOnce(t *testing.T) {
+	// 关闭的服务地址，连接会被拒绝
+	server := httptest.NewServer(http.NotFoundHandler())
+	addr := server.URL
+	server.Close()
+
+	var logs bytes.Buffer
+	c, err := New(
+		config.WithAddresses(addr),
+		config.WithRetry(1, time.Millisecond),
+		config.WithLogger(slog.New(slog.NewJSONHandler(&logs, nil))),
+	)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer c.Close()
+
+	if _, err := c.Do(context.Background(), http.MethodGet, "/", nil); err == nil {
+		t.Fatal("请求应该失败")
+	}
+
+	// 2 次尝试：第 1 次记录为重试，第 2 次记录为错误
+	var messages []string
+	for _, record := range logRecords(t, &logs) {
+		messages = append(messages, record["msg"].(string))
+	}
+	if len(messages) != 2 || messages[0] != "es request retry" || messages[1] != "es request error" {
+		t.Fatalf("每次失败的尝试应该只记录一次，实际 %v", messages)
+	}
+
+	t.Logf("✓ 失败的尝试只记录一次: %v", messages)
+}
+
+func TestRedactBody(t *testing.T) {
+	fields := []string{"password", "token"}
+
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"JSON", `{"user":"a","password":"secret"}`, `{"password":"[REDACTED]","user":"a"}`},
+		{"嵌套", `{"doc":{"auth":[{"token":"t1"},{"name":"x"}]}}`, `{"doc":{"auth":[{"token":"[REDACTED]"},{"name":"x"}]}}`},
+		{"NDJSON", "{\"index\":{\"_id\":\"1\"}}\n{\"password\":\"secret\"}\n", "{\"index\":{\"_id\":\"1\"}}\n{\"password\":\"[REDACTED]\"}"},
+		{"非 JSON", `password=secret`, `password=secret`},
+		{"没有需要脱敏的字段", `{"user":"a"}`, `{"user":"a"}`},
+	}
+
+	for _, tt := range tests {
+		if got := string(redactBody([]byte(tt.body), fields)); got != tt.want {
+			t.Errorf("%s: redactBody = %s，期望 %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFormatBodyForLog(t *testing.T) {
+	secret := `{"password":"secret"}`
+	// 截断位置落在第二个文档中间
+	long := `{"index":{}}` + "\n" + `{"password":"` + strings.Repeat("x", maxLoggedBodySize) + `"}`
+
+	tests := []struct {
+		name   string
+		redact []string
+		body   string
+		want   func(string) bool
+	}{
+		{"空", nil, "", func(s string) bool { return s == "" }},
+		{"未配置脱敏", nil, secret, func(s string) bool { return s == secret }},
+		{"脱敏", []string{"password"}, secret, func(s string) bool { return s == `{"password":"[REDACTED]"}` }},
+		{"截断", nil, long, func(s string) bool {
+			return len(s) == maxLoggedBodySize+len("...(truncated)") && strings.HasSuffix(s, "...(truncated)")
+		}},
+		{"截断后只保留完整的行", []string{"password"}, long, func(s string) bool {
+			return s == `{"index":{}}...(truncated)`
+		}},
+		{"截断后没有完整的行", []string{"password"}, long[len(`{"index":{}}`)+1:], func(s string) bool {
+			return s == redactedTruncatedBody
+		}},
+	}
+
+	for _, tt := range tests {
+		c := &Client{config: &config.Config{LogRedactFields: tt.redact}}
+		got := c.formatBodyForLog([]byte(tt.body))
+		if !tt.want(got) {
+			if len(got) > 100 {
+				got = got[:100] + "..."
+			}
+			t.Errorf("%s: formatBodyForLog 结果不符合预期: %s", tt.name, got)
+		}
+		if tt.redact != nil && (strings.Contains(got, "secret") || strings.Contains(got, "xxx")) {
+			t.Errorf("%s: 日志中不应该包含敏感内容", tt.name)
+		}
+	}
+}
+
+// newEchoServer 创建返回固定响应体的测试服务，可以指定响应延迟
+func newEchoServer(t *testing.T, body string, delay time.Duration) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLogging_DebugRequest(t *testing.T) {
+	server := newEchoServer(t, `{"token":"abc","result":"created"}`, 0)
+
+	var logs bytes.Buffer
+	c, err := New(
+		config.WithAddresses(server.URL),
+		config.WithLogger(slog.New(slog.NewJSONHandler(&logs, nil))), // 默认 Info 级别
+		config.WithLogRedact("password", "token"),
+	)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer c.Close()
+
+	// 普通请求在 Info 级别下不输出
+	body := map[string]interface{}{"user": "a", "password": "secret"}
+	if _, err := c.Do(context.Background(), http.MethodPost, "/test/_doc", body); err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	if logs.Len() != 0 {
+		t.Fatalf("普通请求在 Info 级别下不应该输出日志: %s", logs.String())
+	}
+
+	// 调用 Debug() 的请求以 Info 级别输出脱敏后的请求/响应体
+	resp, err := c.Do(WithDebug(context.Background()), http.MethodPost, "/test/_doc", body)
+	if err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	if !strings.Contains(string(resp), "abc") {
+		t.Fatalf("脱敏不应该影响返回的响应体: %s", resp)
+	}
+
+	records := logRecords(t, &logs)
+	if len(records) != 2 {
+		t.Fatalf("应该输出 2 条日志，实际 %d", len(records))
+	}
+	start, end := records[0], records[1]
+	if start["msg"] != "es request start" || start["level"] != "INFO" {
+		t.Fatalf("第一条日志应该是 Info 级别的 es request start: %v", start)
+	}
+	if reqBody, _ := start["request_body"].(string); !strings.Contains(reqBody, `"password":"[REDACTED]"`) || strings.Contains(reqBody, "secret") {
+		t.Fatalf("请求体应该被脱敏: %s", reqBody)
+	}
+	if end["msg"] != "es request end" || end["status"] != float64(http.StatusOK) {
+		t.Fatalf("第二条日志应该是 es request end: %v", end)
+	}
+	if respBody, _ := end["response_body"].(string); respBody != `{"result":"created","token":"[REDACTED]"}` {
+		t.Fatalf("响应体应该被脱敏: %s", respBody)
+	}
+
+	t.Logf("✓ Debug() 请求输出脱敏后的请求/响应体")
+}
+
+func TestLogging_SlowRequest(t *testing.T) {
+	server := newEchoServer(t, `{}`, 20*time.Millisecond)
+
+	var logs bytes.Buffer
+	c, err := New(
+		config.WithAddresses(server.URL),
+		config.WithLogger(slog.New(slog.NewJSONHandler(&logs, &slog.HandlerOptions{Level: slog.LevelWarn}))),
+		config.WithSlowRequestThreshold(10*time.Millisecond),
+	)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer c.Close()
+
+	if _, err := c.Do(context.Background(), http.MethodGet, "/test/_search", nil); err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+
+	records := logRecords(t, &logs)
+	if len(records) != 1 || records[0]["msg"] != "es slow request" || records[0]["level"] != "WARN" {
+		t.Fatalf("应该输出 1 条 Warn 级别的慢请求日志，实际 %v", records)
+	}
+	if records[0]["path"] != "/test/_search" || records[0]["threshold"] == nil {
+		t.Fatalf("慢请求日志缺少字段: %v", records[0])
+	}
+
+	t.Logf("✓ 慢请求输出 Warn 日志")
+}
@@ -0,0 +1,37 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Kirby980/go-es/errors"
+)
+
+// observeRequest 记录一次请求尝试
+func (c *Client) observeRequest(endpoint string, status int, duration time.Duration, sent, received int) {
+	if c.metrics != nil {
+		c.metrics.ObserveRequest(endpoint, status, duration, int64(sent), int64(received))
+	}
+}
+
+// observeError 记录一次失败的请求
+func (c *Client) observeError(endpoint, errType string) {
+	if c.metrics != nil {
+		c.metrics.ObserveError(endpoint, errType)
+	}
+}
+
+// observeRetry 记录一次重试
+func (c *Client) observeRetry(endpoint string) {
+	if c.metrics != nil {
+		c.metrics.ObserveRetry(endpoint)
+	}
+}
+
+// errorType 返回 ES 错误类型，响应中没有错误类型时使用状态码
+func errorType(err *errors.ESError) string {
+	if err.Type != "" {
+		return err.Type
+	}
+	return fmt.Sprintf("http_%d", err.StatusCode)
+}
@@ -0,0 +1,124 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Kirby980/go-es/config"
+	"github.com/Kirby980/go-es/metrics"
+)
+
+func TestClient_Metrics(t *testing.T) {
+	var count int32
+	server := newStatusServer(t, http.StatusServiceUnavailable, 1, &count)
+
+	m := metrics.NewInMemory()
+	c, err := New(
+		config.WithAddresses(server.URL),
+		config.WithRetry(1, time.Millisecond),
+		config.WithMetricsCollector(m),
+	)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	// 第一次尝试返回 503，重试后成功
+	if _, err := c.Do(ctx, http.MethodPost, "/products/_search", map[string]interface{}{"size": 1}); err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+
+	s := m.Snapshot()
+	search := s.Endpoints["POST _search"]
+	if search.Requests != 2 || search.Retries != 1 || search.Errors != 0 {
+		t.Fatalf("POST _search 应该记录 2 次请求、1 次重试、0 次错误: %+v", search)
+	}
+	if search.Statuses[http.StatusServiceUnavailable] != 1 || search.Statuses[http.StatusOK] != 1 {
+		t.Fatalf("POST _search 的状态码统计不正确: %v", search.Statuses)
+	}
+	if s.BytesSent == 0 || s.BytesReceived == 0 {
+		t.Fatalf("应该统计收发字节数: %d、%d", s.BytesSent, s.BytesReceived)
+	}
+
+	t.Logf("✓ 记录请求、状态码与重试")
+}
+
+func TestClient_MetricsErrorTypes(t *testing.T) {
+	var count int32
+	notFound := newStatusServer(t, http.StatusNotFound, 1, &count)
+
+	tests := []struct {
+		name     string
+		addr     string
+		ctx      func() (context.Context, context.CancelFunc)
+		wantType string
+	}{
+		{"ES 错误类型", notFound.URL, nil, "test_error"},
+		{"传输层错误", closedServerURL(t), nil, "transport_error"},
+		{"调用方取消", notFound.URL, func() (context.Context, context.CancelFunc) {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			return ctx, cancel
+		}, "context_canceled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := metrics.NewInMemory()
+			c, err := New(config.WithAddresses(tt.addr), config.WithRetry(0, 0), config.WithMetricsCollector(m))
+			if err != nil {
+				t.Fatalf("创建客户端失败: %v", err)
+			}
+			defer c.Close()
+
+			ctx := context.Background()
+			if tt.ctx != nil {
+				var cancel context.CancelFunc
+				ctx, cancel = tt.ctx()
+				defer cancel()
+			}
+			if _, err := c.Do(ctx, http.MethodGet, "/products/_doc/1", nil); err == nil {
+				t.Fatal("请求应该失败")
+			}
+
+			s := m.Snapshot()
+			if s.Errors[tt.wantType] != 1 || s.Endpoints["GET _doc"].Errors != 1 {
+				t.Fatalf("应该记录 1 次 %s 错误，实际 %v", tt.wantType, s.Errors)
+			}
+		})
+	}
+
+	t.Logf("✓ 按错误类型统计")
+}
+
+func TestClient_MetricsDisabled(t *testing.T) {
+	var count int32
+	server := newCountingServer(t, &count)
+
+	c, err := New(config.WithAddresses(server.URL))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer c.Close()
+	if c.Metrics() != nil {
+		t.Fatal("未开启指标时 Metrics 应该返回 nil")
+	}
+	if _, err := c.Do(context.Background(), http.MethodGet, "/", nil); err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+
+	// 开启指标但未设置收集器时使用内存收集器
+	c, err = New(config.WithAddresses(server.URL), config.WithMetrics(true))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer c.Close()
+	if _, ok := c.Metrics().(*metrics.InMemory); !ok {
+		t.Fatalf("默认应该使用内存收集器，实际 %T", c.Metrics())
+	}
+
+	t.Logf("✓ 指标开关")
+}
@@ -0,0 +1,27 @@
+package client
+
+import (
+	"net/http"
+
+	"github.com/Kirby980/go-es/config"
+)
+
+// buildRoundTrip 将中间件链包装在 HTTP 客户端之外
+// 第一个中间件在最外层，日志中间件在最内层，记录的是经过所有中间件处理后的请求
+func buildRoundTrip(base config.RoundTripFunc, middlewares []config.Middleware, logging config.Middleware) config.RoundTripFunc {
+	rt := logging(base)
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		rt = middlewares[i](rt)
+	}
+	return rt
+}
+
+// HeaderMiddleware 为每个请求设置固定请求头（如 X-Opaque-Id）
+func HeaderMiddleware(key, value string) config.Middleware {
+	return func(next config.RoundTripFunc) config.RoundTripFunc {
+		return func(req *http.Request) (*http.Response, error) {
+			req.Header.Set(key, value)
+			return next(req)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// node 连接池中的单个节点
+type node struct {
+	url         string
+	dead        bool
+	failures    int       // 连续失败次数，用于计算复活等待时间
+	resurrectAt time.Time // 节点可被再次尝试的时间
+}
+
+// connectionPool 多节点连接池（轮询选择 + 死节点复活）
+type connectionPool struct {
+	mu                sync.Mutex
+	nodes             []*node
+	current           int
+	resurrectTimeout  time.Duration // 首次标记死亡后的复活等待时间
+	resurrectMaxDelay time.Duration // 复活等待时间上限
+}
+
+// newConnectionPool 创建连接池
+func newConnectionPool(addresses []string, resurrectTimeout, resurrectMaxDelay time.Duration) *connectionPool {
+	p := &connectionPool{
+		resurrectTimeout:  resurrectTimeout,
+		resurrectMaxDelay: resurrectMaxDelay,
+	}
+	p.nodes = buildNodes(addresses)
+	return p
+}
+
+// buildNodes 根据地址列表创建节点
+func buildNodes(addresses []string) []*node {
+	nodes := make([]*node, 0, len(addresses))
+	for _, addr := range addresses {
+		nodes = append(nodes, &node{url: strings.TrimRight(addr, "/")})
+	}
+	return nodes
+}
+
+// next 按轮询方式选择下一个可用节点
+// 所有节点都不可用时，强制复活最早到期的死节点，保证请求始终有节点可用
+func (p *connectionPool) next() (*node, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.nodes) == 0 {
+		return nil, fmt.Errorf("连接池中没有可用节点")
+	}
+
+	now := time.Now()
+	for i := 0; i < len(p.nodes); i++ {
+		n := p.nodes[p.current%len(p.nodes)]
+		p.current = (p.current + 1) % len(p.nodes)
+
+		if !n.dead {
+			return n, nil
+		}
+		// 死节点到达复活时间后，允许再次尝试
+		if !now.Before(n.resurrectAt) {
+			n.dead = false
+			return n, nil
+		}
+	}
+
+	// 全部节点都处于死亡状态，选择最早可复活的节点
+	candidate := p.nodes[0]
+	for _, n := range p.nodes[1:] {
+		if n.resurrectAt.Before(candidate.resurrectAt) {
+			candidate = n
+		}
+	}
+	candidate.dead = false
+	return candidate, nil
+}
+
+// peek 返回下一次 next 会选择的节点，不移动轮询位置，也不复活死节点
+func (p *connectionPool) peek() (*node, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.nodes) == 0 {
+		return nil, fmt.Errorf("连接池中没有可用节点")
+	}
+
+	now := time.Now()
+	for i := 0; i < len(p.nodes); i++ {
+		n := p.nodes[(p.current+i)%len(p.nodes)]
+		if !n.dead || !now.Before(n.resurrectAt) {
+			return n, nil
+		}
+	}
+
+	// 全部节点都处于死亡状态，返回最早可复活的节点
+	candidate := p.nodes[0]
+	for _, n := range p.nodes[1:] {
+		if n.resurrectAt.Before(candidate.resurrectAt) {
+			candidate = n
+		}
+	}
+	return candidate, nil
+}
+
+// markDead 标记节点死亡，复活等待时间按连续失败次数指数增长
+func (p *connectionPool) markDead(n *node) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n.dead = true
+	n.failures++
+
+	timeout := p.resurrectTimeout
+	for i := 1; i < n.failures && timeout < p.resurrectMaxDelay; i++ {
+		timeout *= 2
+	}
+	if timeout > p.resurrectMaxDelay {
+		timeout = p.resurrectMaxDelay
+	}
+	n.resurrectAt = time.Now().Add(timeout)
+}
+
+// markAlive 标记节点存活，重置失败计数
+func (p *connectionPool) markAlive(n *node) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n.dead = false
+	n.failures = 0
+	n.resurrectAt = time.Time{}
+}
+
+// urls 返回连接池中的全部节点地址
+func (p *connectionPool) urls() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	urls := make([]string, 0, len(p.nodes))
+	for _, n := range p.nodes {
+		urls = append(urls, n.url)
+	}
+	return urls
+}
+
+// liveCount 返回当前存活节点数量
+func (p *connectionPool) liveCount() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	count := 0
+	for _, n := range p.nodes {
+		if !n.dead {
+			count++
+		}
+	}
+	return count
+}
+
+// setURLs 替换连接池的节点列表，已存在节点的状态会被保留
+func (p *connectionPool) setURLs(addresses []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	existing := make(map[string]*node, len(p.nodes))
+	for _, n := range p.nodes {
+		existing[n.url] = n
+	}
+
+	nodes := buildNodes(addresses)
+	for i, n := range nodes {
+		if old, ok := existing[n.url]; ok {
+			nodes[i] = old
+		}
+	}
+
+	p.nodes = nodes
+	p.current = 0
+}
@@ -0,0 +1,187 @@
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConnectionPool_PeekDoesNotAdvance(t *testing.T) {
+	p := newConnectionPool([]string{"http://a:9200", "http://b:9200"}, time.Minute, time.Hour)
+
+	// 多次 peek 不移动轮询位置
+	for i := 0; i < 3; i++ {
+		n, err := p.peek()
+		if err != nil {
+			t.Fatalf("peek 失败: %v", err)
+		}
+		if n.url != "http://a:9200" {
+			t.Fatalf("peek 应该返回 http://a:9200，实际 %s", n.url)
+		}
+	}
+
+	// peek 返回的节点与下一次 next 一致
+	first, _ := p.next()
+	if first.url != "http://a:9200" {
+		t.Fatalf("next 应该返回 http://a:9200，实际 %s", first.url)
+	}
+	peeked, _ := p.peek()
+	second, _ := p.next()
+	if peeked != second || second.url != "http://b:9200" {
+		t.Fatalf("peek 和 next 应该都返回 http://b:9200，实际 %s、%s", peeked.url, second.url)
+	}
+
+	t.Logf("✓ peek 不移动轮询位置")
+}
+
+func TestConnectionPool_PeekDoesNotResurrect(t *testing.T) {
+	p := newConnectionPool([]string{"http://a:9200", "http://b:9200"}, time.Minute, time.Hour)
+	a := p.nodes[0]
+	p.markDead(a)
+	a.resurrectAt = time.Now().Add(-time.Second) // 已到复活时间
+
+	n, err := p.peek()
+	if err != nil {
+		t.Fatalf("peek 失败: %v", err)
+	}
+	if n != a {
+		t.Fatalf("peek 应该返回到期的死节点 %s，实际 %s", a.url, n.url)
+	}
+	if !a.dead {
+		t.Fatal("peek 不应该复活死节点")
+	}
+	if p.liveCount() != 1 {
+		t.Fatalf("存活节点应该为 1，实际 %d", p.liveCount())
+	}
+
+	// 全部死亡时返回最早可复活的节点，同样不复活
+	b := p.nodes[1]
+	p.markDead(b)
+	a.resurrectAt = time.Now().Add(time.Hour)
+	b.resurrectAt = time.Now().Add(time.Minute)
+	n, _ = p.peek()
+	if n != b || !b.dead {
+		t.Fatalf("peek 应该返回最早可复活的 %s 且不复活，实际 %s（dead=%v）", b.url, n.url, b.dead)
+	}
+
+	t.Logf("✓ peek 不复活死节点")
+}
+
+// poolURLs 依次调用 next 返回的节点地址
+func poolURLs(t *testing.T, p *connectionPool, n int) []string {
+	t.Helper()
+	urls := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		node, err := p.next()
+		if err != nil {
+			t.Fatalf("next 失败: %v", err)
+		}
+		urls = append(urls, node.url)
+	}
+	return urls
+}
+
+func TestConnectionPool_RoundRobin(t *testing.T) {
+	p := newConnectionPool([]string{"http://a:9200/", "http://b:9200", "http://c:9200"}, time.Minute, time.Hour)
+
+	got := poolURLs(t, p, 6)
+	want := []string{"http://a:9200", "http://b:9200", "http://c:9200", "http://a:9200", "http://b:9200", "http://c:9200"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("轮询顺序不正确，实际 %v，期望 %v", got, want)
+		}
+	}
+
+	// 空连接池
+	if _, err := newConnectionPool(nil, time.Minute, time.Hour).next(); err == nil {
+		t.Fatal("空连接池应该返回错误")
+	}
+
+	t.Logf("✓ 轮询选择节点")
+}
+
+func TestConnectionPool_DeadAndResurrect(t *testing.T) {
+	p := newConnectionPool([]string{"http://a:9200", "http://b:9200"}, time.Minute, time.Hour)
+	a, b := p.nodes[0], p.nodes[1]
+
+	// 死节点在复活时间之前被跳过
+	p.markDead(a)
+	if p.liveCount() != 1 {
+		t.Fatalf("存活节点应该为 1，实际 %d", p.liveCount())
+	}
+	for _, url := range poolURLs(t, p, 3) {
+		if url != b.url {
+			t.Fatalf("死节点不应该被选中，实际 %s", url)
+		}
+	}
+
+	// 到达复活时间后重新参与轮询
+	a.resurrectAt = time.Now().Add(-time.Second)
+	urls := poolURLs(t, p, 2)
+	if urls[0] != a.url && urls[1] != a.url {
+		t.Fatalf("到期的死节点应该被重新尝试，实际 %v", urls)
+	}
+	if a.dead {
+		t.Fatal("被重新尝试的节点应该标记为存活")
+	}
+
+	// 全部死亡时强制复活最早到期的节点
+	p.markDead(a)
+	p.markDead(b)
+	a.resurrectAt = time.Now().Add(time.Hour)
+	b.resurrectAt = time.Now().Add(time.Minute)
+	n, err := p.next()
+	if err != nil {
+		t.Fatalf("全部死亡时仍然应该返回节点: %v", err)
+	}
+	if n != b || b.dead {
+		t.Fatalf("应该强制复活最早到期的 %s，实际 %s", b.url, n.url)
+	}
+
+	// markAlive 重置失败计数
+	p.markAlive(a)
+	if a.dead || a.failures != 0 || !a.resurrectAt.IsZero() {
+		t.Fatalf("markAlive 应该重置节点状态: %+v", a)
+	}
+
+	t.Logf("✓ 死节点跳过与复活")
+}
+
+func TestConnectionPool_ResurrectBackoff(t *testing.T) {
+	p := newConnectionPool([]string{"http://a:9200"}, time.Minute, 5*time.Minute)
+	n := p.nodes[0]
+
+	// 连续失败时复活等待时间翻倍，不超过上限
+	want := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+	for i, w := range want {
+		before := time.Now()
+		p.markDead(n)
+		wait := n.resurrectAt.Sub(before)
+		if wait < w || wait > w+time.Second {
+			t.Fatalf("第 %d 次失败后的复活等待时间应该约为 %v，实际 %v", i+1, w, wait)
+		}
+	}
+
+	t.Logf("✓ 复活等待时间指数增长并受上限限制")
+}
+
+func TestConnectionPool_SetURLs(t *testing.T) {
+	p := newConnectionPool([]string{"http://a:9200", "http://b:9200"}, time.Minute, time.Hour)
+	p.markDead(p.nodes[1])
+	p.next()
+
+	p.setURLs([]string{"http://b:9200", "http://c:9200"})
+
+	urls := p.urls()
+	if len(urls) != 2 || urls[0] != "http://b:9200" || urls[1] != "http://c:9200" {
+		t.Fatalf("节点列表应该被替换，实际 %v", urls)
+	}
+	// 保留已存在节点的状态，轮询从头开始
+	if !p.nodes[0].dead {
+		t.Fatal("已存在节点的死亡状态应该保留")
+	}
+	if n, _ := p.next(); n.url != "http://c:9200" {
+		t.Fatalf("应该跳过死节点选择 http://c:9200，实际 %s", n.url)
+	}
+
+	t.Logf("✓ 替换节点列表并保留状态")
+}
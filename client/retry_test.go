@@ -0,0 +1,243 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Kirby980/go-es/config"
+	"github.com/Kirby980/go-es/errors"
+)
+
+// newStatusServer 创建测试服务，前 failures 次请求返回 status，之后返回 200
+func newStatusServer(t *testing.T, status int, failures int32, count *int32) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		if atomic.AddInt32(count, 1) <= failures {
+			w.WriteHeader(status)
+			fmt.Fprintf(w, `{"error":{"type":"test_error","reason":"test"},"status":%d}`, status)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_Retry(t *testing.T) {
+	tests := []struct {
+		name      string
+		status    int
+		failures  int32
+		ctx       func(context.Context) context.Context
+		wantCount int32
+		wantErr   bool
+	}{
+		{"429 重试后成功", http.StatusTooManyRequests, 2, nil, 3, false},
+		{"503 超过最大重试次数", http.StatusServiceUnavailable, 10, nil, 3, true},
+		{"400 不重试", http.StatusBadRequest, 1, nil, 1, true},
+		{"禁用重试", http.StatusServiceUnavailable, 1, WithoutRetry, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var count int32
+			server := newStatusServer(t, tt.status, tt.failures, &count)
+
+			c, err := New(config.WithAddresses(server.URL), config.WithRetry(2, time.Millisecond))
+			if err != nil {
+				t.Fatalf("创建客户端失败: %v", err)
+			}
+			defer c.Close()
+
+			ctx := context.Background()
+			if tt.ctx != nil {
+				ctx = tt.ctx(ctx)
+			}
+			_, err = c.Do(ctx, http.MethodPost, "/test/_doc", map[string]interface{}{"n": 1})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("错误不符合预期: %v", err)
+			}
+			if err != nil {
+				esErr, ok := err.(*errors.ESError)
+				if !ok || esErr.StatusCode != tt.status {
+					t.Fatalf("应该返回状态码 %d 的 ESError，实际 %v", tt.status, err)
+				}
+			}
+			if count != tt.wantCount {
+				t.Fatalf("应该发送 %d 次请求，实际 %d", tt.wantCount, count)
+			}
+		})
+	}
+}
+
+// countingPolicy 记录调用次数的重试策略，最多重试一次且不等待
+type countingPolicy struct {
+	calls int32
+}
+
+func (p *countingPolicy) ShouldRetry(attempt int, resp *http.Response, err error) bool {
+	atomic.AddInt32(&p.calls, 1)
+	return attempt < 2
+}
+
+func (p *countingPolicy) Backoff(attempt int, resp *http.Response) time.Duration {
+	return 0
+}
+
+func TestClient_CustomRetryPolicy(t *testing.T) {
+	var count int32
+	// 500 默认不重试，自定义策略重试
+	server := newStatusServer(t, http.StatusInternalServerError, 1, &count)
+
+	policy := &countingPolicy{}
+	c, err := New(config.WithAddresses(server.URL), config.WithRetryPolicy(policy))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer c.Close()
+
+	if _, err := c.Do(context.Background(), http.MethodGet, "/", nil); err != nil {
+		t.Fatalf("自定义策略重试后应该成功: %v", err)
+	}
+	if count != 2 || policy.calls != 1 {
+		t.Fatalf("应该发送 2 次请求并调用 1 次策略，实际 %d、%d", count, policy.calls)
+	}
+
+	t.Logf("✓ 使用自定义重试策略")
+}
+
+func TestClient_StreamBodyNotRetried(t *testing.T) {
+	var count int32
+	server := newStatusServer(t, http.StatusServiceUnavailable, 1, &count)
+
+	c, err := New(config.WithAddresses(server.URL), config.WithRetry(2, time.Millisecond))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	// 只能读取一次的请求体不重试
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write([]byte(`{"query":{"match_all":{}}}`))
+		pw.Close()
+	}()
+	if _, err := c.Stream(ctx, http.MethodPost, "/test/_search", pr); err == nil {
+		t.Fatal("不能重放的请求体失败后不应该重试")
+	}
+	if count != 1 {
+		t.Fatalf("应该只发送 1 次请求，实际 %d", count)
+	}
+
+	// 内存中的请求体可以重放，失败后重试
+	atomic.StoreInt32(&count, 0)
+	server = newStatusServer(t, http.StatusServiceUnavailable, 1, &count)
+	c, err = New(config.WithAddresses(server.URL), config.WithRetry(2, time.Millisecond))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer c.Close()
+	body, err := c.Stream(ctx, http.MethodPost, "/test/_search", strings.NewReader(`{"query":{"match_all":{}}}`))
+	if err != nil {
+		t.Fatalf("内存中的请求体应该重试后成功: %v", err)
+	}
+	body.Close()
+	if count != 2 {
+		t.Fatalf("应该发送 2 次请求，实际 %d", count)
+	}
+
+	t.Logf("✓ 不能重放的请求体不重试")
+}
+
+// allocatedBytes 返回执行 f 期间分配的内存字节数
+func allocatedBytes(f func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	f()
+	runtime.ReadMemStats(&after)
+	return after.TotalAlloc - before.TotalAlloc
+}
+
+func TestClient_StreamMemoryBodyNotCopied(t *testing.T) {
+	const size = 8 << 20
+	payload := bytes.Repeat([]byte("{\"index\":{}}\n{\"n\":1}\n"), size/21)
+
+	var (
+		count    int32
+		received int64
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := io.Copy(io.Discard, r.Body)
+		atomic.StoreInt64(&received, n)
+		if atomic.AddInt32(&count, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	c, err := New(config.WithAddresses(server.URL), config.WithRetry(1, time.Millisecond))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer c.Close()
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		do   func() (io.ReadCloser, error)
+	}{
+		{"Stream bytes.Buffer", func() (io.ReadCloser, error) {
+			return c.Stream(ctx, http.MethodPost, "/_bulk", bytes.NewBuffer(payload))
+		}},
+		{"Stream bytes.Reader", func() (io.ReadCloser, error) {
+			return c.Stream(ctx, http.MethodPost, "/_bulk", bytes.NewReader(payload))
+		}},
+		{"DoStream GetBody", func() (io.ReadCloser, error) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/_bulk", bytes.NewReader(payload))
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Content-Type", "application/x-ndjson")
+			return c.DoStream(ctx, req)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			atomic.StoreInt32(&count, 0)
+			var err error
+			allocated := allocatedBytes(func() {
+				var body io.ReadCloser
+				if body, err = tt.do(); err == nil {
+					body.Close()
+				}
+			})
+			if err != nil {
+				t.Fatalf("请求应该在重试后成功: %v", err)
+			}
+			if count != 2 || received != int64(len(payload)) {
+				t.Fatalf("重试应该发送完整的请求体，实际请求 %d 次、最后一次 %d 字节", count, received)
+			}
+			// 请求体直接从调用方的内存读取，两次尝试分配的内存应该远小于请求体
+			if allocated > size/4 {
+				t.Fatalf("内存中的请求体不应该被复制，%d 字节的请求体分配了 %d 字节", len(payload), allocated)
+			}
+		})
+	}
+
+	t.Logf("✓ 内存中的请求体不复制且可以重试")
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// nodesHTTPResponse /_nodes/http 响应
+type nodesHTTPResponse struct {
+	Nodes map[string]struct {
+		Name       string            `json:"name"`
+		Roles      []string          `json:"roles"`
+		Attributes map[string]string `json:"attributes"`
+		HTTP       struct {
+			PublishAddress string `json:"publish_address"`
+		} `json:"http"`
+	} `json:"nodes"`
+}
+
+// Sniff 嗅探集群节点，并用发现的 HTTP 地址替换连接池
+func (c *Client) Sniff(ctx context.Context) error {
+	if c.config.SniffTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.SniffTimeout)
+		defer cancel()
+	}
+
+	respBody, err := c.perform(ctx, http.MethodGet, "/_nodes/http", nil, nil)
+	if err != nil {
+		return fmt.Errorf("嗅探节点失败: %w", err)
+	}
+
+	var resp nodesHTTPResponse
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return fmt.Errorf("解析节点信息失败: %w", err)
+	}
+
+	scheme := c.sniffScheme()
+	addresses := make([]string, 0, len(resp.Nodes))
+	for _, n := range resp.Nodes {
+		if n.HTTP.PublishAddress == "" {
+			continue
+		}
+		if c.config.SniffFilter != nil && !c.config.SniffFilter(n.Roles, n.Attributes) {
+			continue
+		}
+		addresses = append(addresses, scheme+"://"+parsePublishAddress(n.HTTP.PublishAddress))
+	}
+
+	if len(addresses) == 0 {
+		return fmt.Errorf("嗅探节点失败: 没有符合条件的节点")
+	}
+
+	// 排序保证轮询顺序稳定
+	sort.Strings(addresses)
+	c.pool.setURLs(addresses)
+
+	return nil
+}
+
+// sniffScheme 使用初始配置地址的协议作为嗅探地址的协议
+func (c *Client) sniffScheme() string {
+	if len(c.config.Addresses) > 0 {
+		if u, err := url.Parse(c.config.Addresses[0]); err == nil && u.Scheme != "" {
+			return u.Scheme
+		}
+	}
+	return "http"
+}
+
+// parsePublishAddress 解析 publish_address
+// 格式可能是 "ip:port" 或 "hostname/ip:port"，后者优先使用 hostname（TLS 证书校验需要）
+func parsePublishAddress(addr string) string {
+	if idx := strings.Index(addr, "/"); idx >= 0 {
+		host := addr[:idx]
+		ipPort := addr[idx+1:]
+		if host == "" {
+			return ipPort
+		}
+		if portIdx := strings.LastIndex(ipPort, ":"); portIdx >= 0 {
+			return host + ipPort[portIdx:]
+		}
+		return host
+	}
+	return addr
+}
+
+// startSniffer 按配置的间隔定时嗅探节点
+func (c *Client) startSniffer() {
+	ticker := time.NewTicker(c.config.SniffInterval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.done:
+				return
+			case <-ticker.C:
+				if err := c.Sniff(context.Background()); err != nil {
+					c.logger.Warn("es sniff failed", slog.Any("error", err))
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Kirby980/go-es/config"
+)
+
+func TestParsePublishAddress(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"10.0.0.1:9200", "10.0.0.1:9200"},
+		{"es-node-1/10.0.0.1:9200", "es-node-1:9200"},
+		{"/10.0.0.1:9200", "10.0.0.1:9200"},
+		{"es-node-1/10.0.0.1", "es-node-1"},
+		{"[::1]:9200", "[::1]:9200"},
+		{"es-node-1/[::1]:9200", "es-node-1:9200"},
+	}
+
+	for _, tt := range tests {
+		if got := parsePublishAddress(tt.addr); got != tt.want {
+			t.Errorf("parsePublishAddress(%q) = %q，期望 %q", tt.addr, got, tt.want)
+		}
+	}
+}
+
+// newSniffServer 创建响应 /_nodes/http 的测试服务
+// 返回的节点：master-only（只有 master 角色）、data-1、data-2（data + ingest）、没有 HTTP 地址的节点
+func newSniffServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/_nodes/http" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"nodes":{
+			"m":  {"name":"master-only","roles":["master"],"http":{"publish_address":"master/10.0.0.1:9200"}},
+			"d2": {"name":"data-2","roles":["data","ingest"],"http":{"publish_address":"10.0.0.3:9200"}},
+			"d1": {"name":"data-1","roles":["data"],"http":{"publish_address":"data-1/10.0.0.2:9200"}},
+			"x":  {"name":"no-http","roles":["data"]}
+		}}`)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_Sniff(t *testing.T) {
+	server := newSniffServer(t)
+
+	tests := []struct {
+		name   string
+		filter config.NodeFilter
+		want   []string
+	}{
+		{"不过滤", nil, []string{"http://10.0.0.3:9200", "http://data-1:9200", "http://master:9200"}},
+		{"排除专用主节点", config.ExcludeDedicatedMasters(), []string{"http://10.0.0.3:9200", "http://data-1:9200"}},
+		{"要求角色", config.RequireRoles("data", "ingest"), []string{"http://10.0.0.3:9200"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := New(config.WithAddresses(server.URL), config.WithSniffFilter(tt.filter))
+			if err != nil {
+				t.Fatalf("创建客户端失败: %v", err)
+			}
+			defer c.Close()
+
+			if err := c.Sniff(context.Background()); err != nil {
+				t.Fatalf("嗅探失败: %v", err)
+			}
+			if got := c.Addresses(); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("嗅探到的地址不正确，实际 %v，期望 %v", got, tt.want)
+			}
+		})
+	}
+
+	// 没有符合条件的节点时返回错误，保留原来的地址
+	c, err := New(config.WithAddresses(server.URL), config.WithSniffFilter(config.RequireRoles("ml")))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer c.Close()
+	if err := c.Sniff(context.Background()); err == nil {
+		t.Fatal("没有符合条件的节点时应该返回错误")
+	}
+	if got := c.Addresses(); len(got) != 1 || got[0] != server.URL {
+		t.Fatalf("嗅探失败时应该保留原来的地址，实际 %v", got)
+	}
+
+	t.Logf("✓ 嗅探节点并按角色过滤")
+}
+
+func TestClient_SniffOnStartFailure(t *testing.T) {
+	// 启动时嗅探失败不影响客户端创建
+	addr := closedServerURL(t)
+	c, err := New(config.WithAddresses(addr), config.WithSniff(0), config.WithRetry(0, 0))
+	if err != nil {
+		t.Fatalf("启动时嗅探失败不应该导致创建失败: %v", err)
+	}
+	defer c.Close()
+
+	if got := c.Addresses(); len(got) != 1 || got[0] != addr {
+		t.Fatalf("应该继续使用配置的地址，实际 %v", got)
+	}
+
+	t.Logf("✓ 启动时嗅探失败继续使用配置的地址")
+}
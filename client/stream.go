@@ -0,0 +1,138 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/Kirby980/go-es/tracing"
+)
+
+// Stream 以流式方式执行请求，返回未读取的响应体，调用方负责关闭
+// 适用于大批量写入和大结果集读取，避免在内存中保留完整的请求/响应；状态码 >= 400 时返回 *errors.ESError
+// 内存中的请求体（*bytes.Buffer、*bytes.Reader、*strings.Reader）直接引用底层数据，不复制，可以重放，失败时按重试策略重试；
+// 其他请求体（如 io.Pipe）边读边发送，只能发送一次，不会自动重试
+func (c *Client) Stream(ctx context.Context, method, path string, body io.Reader) (io.ReadCloser, error) {
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+
+	return c.stream(ctx, method, path, header, streamRequestBody(body))
+}
+
+// DoStream 以流式方式执行自定义 HTTP 请求（如需要设置 Content-Type 为 application/x-ndjson 的请求）
+// 请求的 scheme 和 host 会被替换为连接池选中的节点
+// 设置了 GetBody 的请求体（http.NewRequest 传入 *bytes.Buffer、*bytes.Reader、*strings.Reader 时自动设置）
+// 每次尝试通过 GetBody 重新读取，不会复制到内存，失败时按重试策略重试
+func (c *Client) DoStream(ctx context.Context, req *http.Request) (io.ReadCloser, error) {
+	var body requestBody
+	switch {
+	case req.Body == nil || req.Body == http.NoBody:
+	case req.GetBody != nil:
+		req.Body.Close()
+		body = requestBody{open: req.GetBody, length: req.ContentLength}
+	default:
+		body.stream = &countingReader{r: req.Body}
+	}
+
+	return c.stream(ctx, req.Method, req.URL.RequestURI(), req.Header, body)
+}
+
+// memoryReader 内存中的读取器（*bytes.Reader、*strings.Reader）
+type memoryReader interface {
+	io.ReaderAt
+	io.Seeker
+	Len() int
+	Size() int64
+}
+
+// streamRequestBody 将 Stream 的请求体转换为 requestBody
+// 内存中的请求体直接引用底层数据，不复制；读取位置移动到末尾，与读取完成后一致
+func streamRequestBody(body io.Reader) requestBody {
+	switch r := body.(type) {
+	case nil:
+		return requestBody{}
+	case *bytes.Buffer:
+		return requestBody{data: r.Next(r.Len())}
+	case memoryReader:
+		offset, length := r.Size()-int64(r.Len()), int64(r.Len())
+		r.Seek(0, io.SeekEnd)
+		return requestBody{
+			open: func() (io.ReadCloser, error) {
+				return io.NopCloser(io.NewSectionReader(r, offset, length)), nil
+			},
+			length: length,
+		}
+	default:
+		return requestBody{stream: &countingReader{r: body}}
+	}
+}
+
+// stream 执行流式请求，响应体关闭时记录指标并结束 span
+func (c *Client) stream(ctx context.Context, method, path string, header http.Header, reqBody requestBody) (io.ReadCloser, error) {
+	var span tracing.Span
+	if c.tracer != nil {
+		ctx, span = c.startSpan(ctx, method, path, reqBody.head(maxStatementInput))
+	}
+
+	resp, finish, err := c.execute(ctx, method, path, header, reqBody)
+	if err != nil {
+		if span != nil {
+			endSpan(span, err)
+		}
+		return nil, err
+	}
+
+	return &streamBody{
+		body: resp.Body,
+		done: func(received int, err error) {
+			finish(received, err)
+			if span != nil {
+				endSpan(span, err)
+			}
+		},
+	}, nil
+}
+
+// countingReader 统计已读取的字节数
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+// Read 读取数据
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+// streamBody 流式响应体，关闭时记录读取的字节数和读取错误
+type streamBody struct {
+	body     io.ReadCloser
+	received int
+	err      error
+	done     func(received int, err error)
+	once     sync.Once
+}
+
+// Read 读取响应体
+func (b *streamBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	b.received += n
+	if err != nil && err != io.EOF && b.err == nil {
+		b.err = fmt.Errorf("读取响应失败: %w", err)
+	}
+	return n, err
+}
+
+// Close 关闭响应体
+func (b *streamBody) Close() error {
+	err := b.body.Close()
+	b.once.Do(func() {
+		b.done(b.received, b.err)
+	})
+	return err
+}
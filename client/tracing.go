@@ -0,0 +1,221 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Kirby980/go-es/tracing"
+)
+
+// maxStatementSize 记录到 span 的查询语句最大长度
+const maxStatementSize = 4 * 1024
+
+// maxStatementInput 生成查询语句时最多读取的请求体长度（只对需要重新读取的请求体生效），截断处不完整的行会被跳过
+const maxStatementInput = 64 * 1024
+
+// Tracer 链路追踪接口（定义在 tracing 包中，避免 config 与 client 循环依赖）
+type Tracer = tracing.Tracer
+
+// Span 链路追踪 span
+type Span = tracing.Span
+
+type spanKey struct{}
+
+type operationKey struct{}
+
+// WithOperation 为该 context 发出的请求指定链路追踪的操作名称（默认根据请求路径推断）
+func WithOperation(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, operationKey{}, name)
+}
+
+// activeSpan 返回当前请求的 span
+func activeSpan(ctx context.Context) tracing.Span {
+	span, _ := ctx.Value(spanKey{}).(tracing.Span)
+	return span
+}
+
+// startSpan 为一次客户端调用（包括所有重试）创建 span
+func (c *Client) startSpan(ctx context.Context, method, path string, body []byte) (context.Context, tracing.Span) {
+	name, _ := ctx.Value(operationKey{}).(string)
+	if name == "" {
+		name = operationName(method, path)
+	}
+
+	ctx, span := c.tracer.Start(ctx, name)
+	ctx = context.WithValue(ctx, spanKey{}, span)
+
+	attrs := []tracing.Attribute{
+		tracing.Attr(tracing.AttrDBSystem, tracing.DBSystemElasticsearch),
+		tracing.Attr(tracing.AttrDBOperation, name),
+		tracing.Attr(tracing.AttrHTTPMethod, method),
+		tracing.Attr(tracing.AttrURLPath, pathOnly(path)),
+	}
+	if index := indexOf(path); index != "" {
+		attrs = append(attrs, tracing.Attr(tracing.AttrIndex, index))
+	}
+	if statement := sanitizeStatement(body); statement != "" {
+		attrs = append(attrs, tracing.Attr(tracing.AttrDBStatement, statement))
+	}
+	span.SetAttributes(attrs...)
+
+	return ctx, span
+}
+
+// traceAttempt 为单次请求尝试设置节点属性并传播 traceparent
+func traceAttempt(req *http.Request, attempt int) {
+	span := activeSpan(req.Context())
+	if span == nil {
+		return
+	}
+	span.SetAttributes(
+		tracing.Attr(tracing.AttrURLFull, req.URL.String()),
+		tracing.Attr(tracing.AttrServerAddress, req.URL.Host),
+	)
+	if attempt > 1 {
+		span.SetAttributes(tracing.Attr(tracing.AttrRetryCount, attempt-1))
+	}
+	if traceParent := span.TraceParent(); traceParent != "" {
+		req.Header.Set("traceparent", traceParent)
+	}
+}
+
+// traceStatus 记录响应状态码（重试时以最后一次为准）
+func traceStatus(ctx context.Context, status int) {
+	if span := activeSpan(ctx); span != nil {
+		span.SetAttributes(tracing.Attr(tracing.AttrHTTPStatusCode, status))
+	}
+}
+
+// endSpan 记录错误并结束 span
+func endSpan(span tracing.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
+
+// operationName 根据请求方法和路径推断操作名称（ES API 名称）
+func operationName(method, path string) string {
+	endpoint := endpointOf(path)
+	switch endpoint {
+	case "_doc":
+		switch method {
+		case http.MethodGet:
+			return "get"
+		case http.MethodHead:
+			return "exists"
+		case http.MethodDelete:
+			return "delete"
+		default:
+			return "index"
+		}
+	case "index":
+		switch method {
+		case http.MethodPut:
+			return "indices.create"
+		case http.MethodDelete:
+			return "indices.delete"
+		case http.MethodHead:
+			return "indices.exists"
+		default:
+			return "indices.get"
+		}
+	case "_mapping":
+		if method == http.MethodGet {
+			return "indices.get_mapping"
+		}
+		return "indices.put_mapping"
+	case "_settings":
+		if method == http.MethodGet {
+			return "indices.get_settings"
+		}
+		return "indices.put_settings"
+	case "_search/scroll":
+		if method == http.MethodDelete {
+			return "clear_scroll"
+		}
+		return "scroll"
+	case "root":
+		return "info"
+	case "_nodes", "_nodes/" + nodeIDPlaceholder:
+		return "nodes.info"
+	}
+	endpoint = strings.Replace(endpoint, "/"+nodeIDPlaceholder, "", 1)
+	return strings.ReplaceAll(strings.TrimPrefix(endpoint, "_"), "/", ".")
+}
+
+// indexOf 返回请求路径中的索引名称（第一段不以下划线开头时）
+func indexOf(path string) string {
+	segments := strings.Split(strings.Trim(pathOnly(path), "/"), "/")
+	if segments[0] == "" || strings.HasPrefix(segments[0], "_") {
+		return ""
+	}
+	index, err := url.PathUnescape(segments[0])
+	if err != nil {
+		return segments[0]
+	}
+	return index
+}
+
+// pathOnly 去掉路径中的查询参数
+func pathOnly(path string) string {
+	if idx := strings.IndexByte(path, '?'); idx >= 0 {
+		return path[:idx]
+	}
+	return path
+}
+
+// sanitizeStatement 将查询语句中的字面值替换为 "?"，只保留结构，避免泄露业务数据
+// NDJSON 请求体逐行处理
+func sanitizeStatement(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	lines := bytes.Split(bytes.TrimRight(body, "\n"), []byte("\n"))
+	out := make([]string, 0, len(lines))
+	size := 0
+	for _, line := range lines {
+		var v interface{}
+		if err := json.Unmarshal(line, &v); err != nil {
+			continue
+		}
+		data, err := json.Marshal(sanitizeValue(v))
+		if err != nil {
+			continue
+		}
+		out = append(out, string(data))
+		size += len(data)
+		if size > maxStatementSize {
+			break
+		}
+	}
+
+	statement := strings.Join(out, "\n")
+	if len(statement) > maxStatementSize {
+		statement = statement[:maxStatementSize] + "...(truncated)"
+	}
+	return statement
+}
+
+// sanitizeValue 递归替换字面值
+func sanitizeValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = sanitizeValue(item)
+		}
+		return val
+	case []interface{}:
+		for i, item := range val {
+			val[i] = sanitizeValue(item)
+		}
+		return val
+	default:
+		return "?"
+	}
+}
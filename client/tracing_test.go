@@ -0,0 +1,204 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Kirby980/go-es/config"
+	"github.com/Kirby980/go-es/tracing"
+)
+
+func TestSanitizeStatement(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{"空", "", ""},
+		{"JSON", `{"query":{"term":{"user":"kimchy"}},"size":10}`, `{"query":{"term":{"user":"?"}},"size":"?"}`},
+		{"数组", `{"terms":{"id":[1,2,3]}}`, `{"terms":{"id":["?","?","?"]}}`},
+		{"NDJSON", "{\"index\":{}}\n{\"query\":{\"match_all\":{}}}\n", "{\"index\":{}}\n{\"query\":{\"match_all\":{}}}"},
+		{"NDJSON 字面值", "{\"index\":{\"_id\":\"1\"}}\n{\"title\":\"secret\"}\n", "{\"index\":{\"_id\":\"?\"}}\n{\"title\":\"?\"}"},
+		{"非 JSON 的行被跳过", "not json\n{\"a\":1}", `{"a":"?"}`},
+	}
+
+	for _, tt := range tests {
+		if got := sanitizeStatement([]byte(tt.body)); got != tt.want {
+			t.Errorf("%s: sanitizeStatement = %q，期望 %q", tt.name, got, tt.want)
+		}
+	}
+
+	// 超长语句被截断
+	long := `{"terms":{"id":[` + strings.Repeat(`1,`, maxStatementSize) + `1]}}`
+	if got := sanitizeStatement([]byte(long)); len(got) != maxStatementSize+len("...(truncated)") || !strings.HasSuffix(got, "...(truncated)") {
+		t.Errorf("超长语句应该被截断到 %d 字节，实际 %d", maxStatementSize, len(got))
+	}
+}
+
+func TestOperationName(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{http.MethodGet, "/", "info"},
+		{http.MethodGet, "/products/_doc/1", "get"},
+		{http.MethodHead, "/products/_doc/1", "exists"},
+		{http.MethodDelete, "/products/_doc/1", "delete"},
+		{http.MethodPut, "/products/_doc/1", "index"},
+		{http.MethodPut, "/products", "indices.create"},
+		{http.MethodDelete, "/products", "indices.delete"},
+		{http.MethodHead, "/products", "indices.exists"},
+		{http.MethodGet, "/products/_mapping", "indices.get_mapping"},
+		{http.MethodPut, "/products/_mapping", "indices.put_mapping"},
+		{http.MethodPut, "/products/_settings", "indices.put_settings"},
+		{http.MethodPost, "/_search/scroll", "scroll"},
+		{http.MethodDelete, "/_search/scroll", "clear_scroll"},
+		{http.MethodPost, "/products/_search", "search"},
+		{http.MethodPost, "/_bulk", "bulk"},
+		{http.MethodGet, "/_cluster/health", "cluster.health"},
+		{http.MethodGet, "/_nodes", "nodes.info"},
+		{http.MethodGet, "/_nodes/abc123", "nodes.info"},
+		{http.MethodGet, "/_nodes/abc123/stats", "nodes.stats"},
+	}
+
+	for _, tt := range tests {
+		if got := operationName(tt.method, tt.path); got != tt.want {
+			t.Errorf("operationName(%s %s) = %q，期望 %q", tt.method, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestIndexOf(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/", ""},
+		{"/_bulk", ""},
+		{"/products/_search?size=1", "products"},
+		{"/logs-2024%2A/_search", "logs-2024*"},
+	}
+
+	for _, tt := range tests {
+		if got := indexOf(tt.path); got != tt.want {
+			t.Errorf("indexOf(%q) = %q，期望 %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestClient_Tracing(t *testing.T) {
+	var (
+		mu           sync.Mutex
+		traceParents []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		traceParents = append(traceParents, r.Header.Get("traceparent"))
+		attempt := len(traceParents)
+		mu.Unlock()
+
+		if attempt == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	recorder := tracing.NewRecorder()
+	c, err := New(
+		config.WithAddresses(server.URL),
+		config.WithRetry(1, time.Millisecond),
+		config.WithTracer(recorder),
+	)
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer c.Close()
+
+	// 父 span 与客户端 span 属于同一条链路
+	ctx, parent := recorder.Start(context.Background(), "handler")
+	body := map[string]interface{}{"query": map[string]interface{}{"term": map[string]interface{}{"user": "kimchy"}}}
+	if _, err := c.Do(ctx, http.MethodPost, "/products/_search", body); err != nil {
+		t.Fatalf("请求失败: %v", err)
+	}
+	parent.End()
+
+	spans := recorder.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("应该记录 2 个 span，实际 %d", len(spans))
+	}
+	span, root := spans[0], spans[1]
+	if span.Name != "search" || span.TraceID != root.TraceID || span.ParentSpanID != root.SpanID {
+		t.Fatalf("客户端 span 应该是父 span 的子 span: %+v", span)
+	}
+
+	// 重试共用一个 span，每次尝试都传播 traceparent
+	want := tracing.FormatTraceParent(span.TraceID, span.SpanID, true)
+	if len(traceParents) != 2 || traceParents[0] != want || traceParents[1] != want {
+		t.Fatalf("每次尝试都应该发送 traceparent %s，实际 %v", want, traceParents)
+	}
+
+	attrs := span.Attributes
+	checks := map[string]interface{}{
+		tracing.AttrDBSystem:       tracing.DBSystemElasticsearch,
+		tracing.AttrDBOperation:    "search",
+		tracing.AttrHTTPMethod:     http.MethodPost,
+		tracing.AttrURLPath:        "/products/_search",
+		tracing.AttrIndex:          "products",
+		tracing.AttrDBStatement:    `{"query":{"term":{"user":"?"}}}`,
+		tracing.AttrHTTPStatusCode: http.StatusOK,
+		tracing.AttrRetryCount:     1,
+	}
+	for key, want := range checks {
+		if attrs[key] != want {
+			t.Errorf("属性 %s = %v，期望 %v", key, attrs[key], want)
+		}
+	}
+	if span.Err != nil {
+		t.Errorf("成功的请求不应该记录错误: %v", span.Err)
+	}
+
+	t.Logf("✓ 记录 span 并传播 traceparent")
+}
+
+func TestClient_TracingError(t *testing.T) {
+	var count int32
+	server := newStatusServer(t, http.StatusNotFound, 1, &count)
+
+	recorder := tracing.NewRecorder()
+	c, err := New(config.WithAddresses(server.URL), config.WithTracer(recorder))
+	if err != nil {
+		t.Fatalf("创建客户端失败: %v", err)
+	}
+	defer c.Close()
+
+	// 指定操作名称
+	ctx := WithOperation(context.Background(), "custom.get")
+	if _, err := c.Do(ctx, http.MethodGet, "/products/_doc/1", nil); err == nil {
+		t.Fatal("请求应该失败")
+	}
+
+	spans := recorder.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("应该记录 1 个 span，实际 %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name != "custom.get" || span.Err == nil {
+		t.Fatalf("span 应该使用指定的名称并记录错误: %+v", span)
+	}
+	if span.Attributes[tracing.AttrHTTPStatusCode] != http.StatusNotFound {
+		t.Fatalf("应该记录状态码 404，实际 %v", span.Attributes[tracing.AttrHTTPStatusCode])
+	}
+	if _, ok := span.Attributes[tracing.AttrDBStatement]; ok {
+		t.Fatal("没有请求体时不应该记录查询语句")
+	}
+
+	t.Logf("✓ 失败的请求记录错误")
+}
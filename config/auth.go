@@ -0,0 +1,71 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+)
+
+// Credentials 认证凭据
+// 同时设置多种凭据时按 APIKey > BearerToken > Username/Password 的优先级生效
+type Credentials struct {
+	Username    string
+	Password    string
+	APIKey      string // base64(id:api_key) 编码后的 API Key
+	BearerToken string
+}
+
+// IsZero 判断是否未设置任何凭据
+func (c Credentials) IsZero() bool {
+	return c.Username == "" && c.Password == "" && c.APIKey == "" && c.BearerToken == ""
+}
+
+// CredentialsProvider 凭据提供函数，每次请求（包括重试）前调用，可用于轮换密钥
+type CredentialsProvider func(ctx context.Context) (Credentials, error)
+
+// EncodeAPIKey 将 API Key 的 id 和 key 编码为 Authorization 头使用的格式
+func EncodeAPIKey(id, key string) string {
+	return base64.StdEncoding.EncodeToString([]byte(id + ":" + key))
+}
+
+// WithAPIKey 使用 API Key 认证（Authorization: ApiKey ...）
+func WithAPIKey(id, key string) Option {
+	return func(c *Config) {
+		c.APIKey = EncodeAPIKey(id, key)
+	}
+}
+
+// WithEncodedAPIKey 使用已编码的 API Key 认证（创建 API Key 时返回的 encoded 字段）
+func WithEncodedAPIKey(encoded string) Option {
+	return func(c *Config) {
+		c.APIKey = encoded
+	}
+}
+
+// WithBearerToken 使用 Bearer Token 认证（Authorization: Bearer ...）
+func WithBearerToken(token string) Option {
+	return func(c *Config) {
+		c.BearerToken = token
+	}
+}
+
+// WithCredentialsProvider 设置凭据提供函数，设置后忽略静态配置的认证信息
+func WithCredentialsProvider(provider CredentialsProvider) Option {
+	return func(c *Config) {
+		c.CredentialsProvider = provider
+	}
+}
+
+// WithCACert 设置用于验证服务端证书的 CA 证书（PEM 格式）
+func WithCACert(pem []byte) Option {
+	return func(c *Config) {
+		c.CACert = pem
+	}
+}
+
+// WithClientCert 设置双向 TLS 的客户端证书和私钥（PEM 格式）
+func WithClientCert(cert, key []byte) Option {
+	return func(c *Config) {
+		c.ClientCert = cert
+		c.ClientKey = key
+	}
+}
@@ -0,0 +1,61 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// Codec JSON 编解码器，可以替换为更快的实现或自定义数字处理
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+	NewDecoder(r io.Reader) Decoder
+}
+
+// Decoder 流式解码器
+type Decoder interface {
+	Decode(v interface{}) error
+}
+
+// JSONCodec 基于 encoding/json 的默认编解码器
+type JSONCodec struct {
+	// UseNumber 将数字解码为 json.Number 而不是 float64，避免 map[string]interface{} 中的大整数丢失精度
+	UseNumber bool
+}
+
+// Marshal 序列化
+func (c JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal 反序列化
+func (c JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	if !c.UseNumber {
+		return json.Unmarshal(data, v)
+	}
+	return c.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// NewDecoder 创建流式解码器
+func (c JSONCodec) NewDecoder(r io.Reader) Decoder {
+	dec := json.NewDecoder(r)
+	if c.UseNumber {
+		dec.UseNumber()
+	}
+	return dec
+}
+
+// WithCodec 设置 JSON 编解码器，客户端请求体和所有构建器的响应解析都会使用
+func WithCodec(codec Codec) Option {
+	return func(c *Config) {
+		c.Codec = codec
+	}
+}
+
+// WithUseNumber 使用默认编解码器并将数字解码为 json.Number（会覆盖 WithCodec 的设置）
+func WithUseNumber() Option {
+	return func(c *Config) {
+		c.Codec = JSONCodec{UseNumber: true}
+	}
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSONCodec(t *testing.T) {
+	data := []byte(`{"id":9007199254740993,"score":1.5}`)
+
+	tests := []struct {
+		name      string
+		codec     JSONCodec
+		wantID    interface{}
+		wantScore interface{}
+	}{
+		{"默认解码为 float64", JSONCodec{}, float64(9007199254740993), 1.5},
+		{"UseNumber 保留精度", JSONCodec{UseNumber: true}, json.Number("9007199254740993"), json.Number("1.5")},
+	}
+
+	for _, tt := range tests {
+		var v map[string]interface{}
+		if err := tt.codec.Unmarshal(data, &v); err != nil {
+			t.Fatalf("%s: Unmarshal 失败: %v", tt.name, err)
+		}
+		if v["id"] != tt.wantID || v["score"] != tt.wantScore {
+			t.Errorf("%s: Unmarshal = %v，期望 id=%v score=%v", tt.name, v, tt.wantID, tt.wantScore)
+		}
+
+		// 流式解码器与 Unmarshal 一致
+		var streamed map[string]interface{}
+		if err := tt.codec.NewDecoder(strings.NewReader(string(data))).Decode(&streamed); err != nil {
+			t.Fatalf("%s: Decode 失败: %v", tt.name, err)
+		}
+		if streamed["id"] != tt.wantID || streamed["score"] != tt.wantScore {
+			t.Errorf("%s: Decode = %v，期望 id=%v score=%v", tt.name, streamed, tt.wantID, tt.wantScore)
+		}
+	}
+
+	// UseNumber 不影响结构体字段
+	var doc struct {
+		ID int64 `json:"id"`
+	}
+	if err := (JSONCodec{UseNumber: true}).Unmarshal(data, &doc); err != nil || doc.ID != 9007199254740993 {
+		t.Errorf("结构体字段应该正常解码: %d（%v）", doc.ID, err)
+	}
+
+	// 序列化 json.Number 保持原样
+	out, err := (JSONCodec{}).Marshal(map[string]interface{}{"id": json.Number("9007199254740993")})
+	if err != nil || string(out) != `{"id":9007199254740993}` {
+		t.Errorf("Marshal = %s（%v）", out, err)
+	}
+}
+
+func TestWithUseNumber(t *testing.T) {
+	cfg := DefaultConfig()
+	WithCodec(JSONCodec{})(cfg)
+	WithUseNumber()(cfg)
+
+	codec, ok := cfg.Codec.(JSONCodec)
+	if !ok || !codec.UseNumber {
+		t.Fatalf("WithUseNumber 应该覆盖之前设置的编解码器，实际 %#v", cfg.Codec)
+	}
+}
@@ -1,21 +1,47 @@
 package config
 
 import (
+	"compress/gzip"
+	"log/slog"
+	"net/http"
 	"time"
+
+	"github.com/Kirby980/go-es/metrics"
+	"github.com/Kirby980/go-es/tracing"
 )
 
 // Config Elasticsearch 配置
 type Config struct {
 	// ES 服务器地址列表
 	Addresses []string
+	CloudID   string // Elastic Cloud ID，设置后覆盖 Addresses
 
 	// 认证信息
-	Username string
-	Password string
+	Username            string
+	Password            string
+	APIKey              string              // base64(id:api_key) 编码后的 API Key
+	BearerToken         string              // Bearer Token
+	CredentialsProvider CredentialsProvider // 动态凭据，设置后忽略上面的静态凭据
+
+	// TLS 配置
+	CACert     []byte // CA 证书（PEM），为空时使用系统证书
+	ClientCert []byte // 客户端证书（PEM），用于双向 TLS
+	ClientKey  []byte // 客户端私钥（PEM）
 
 	// 连接配置
 	MaxRetries   int
 	RetryBackoff time.Duration
+	RetryPolicy  RetryPolicy // 自定义重试策略，为空时根据 MaxRetries/RetryBackoff 使用指数退避策略
+
+	// 死节点复活配置
+	ResurrectTimeout  time.Duration // 节点首次被标记死亡后的复活等待时间
+	ResurrectMaxDelay time.Duration // 复活等待时间上限（连续失败时按指数增长）
+
+	// 节点嗅探配置
+	SniffOnStart  bool          // 启动时嗅探集群节点
+	SniffInterval time.Duration // 定时嗅探间隔，0 表示不定时嗅探
+	SniffTimeout  time.Duration // 单次嗅探超时时间
+	SniffFilter   NodeFilter    // 嗅探节点过滤，返回 false 的节点不加入连接池
 
 	// 跳过证书验证
 	InsecureSkipVerify bool
@@ -23,9 +49,27 @@ type Config struct {
 	// 超时配置
 	Timeout time.Duration
 
+	// 压缩配置
+	CompressRequestBody  bool // gzip 压缩请求体并接受 gzip 响应
+	CompressionLevel     int  // gzip 压缩级别（gzip.BestSpeed ~ gzip.BestCompression）
+	CompressionThreshold int  // 请求体达到该字节数才压缩
+
+	// 日志配置
+	Logger               *slog.Logger  // 结构化日志，为空时不输出日志（开启调试时输出到标准输出）
+	SlowRequestThreshold time.Duration // 慢请求阈值，超过后输出 Warn 日志，0 表示不检测
+	LogRedactFields      []string      // 日志中需要脱敏的请求/响应体字段名
+
+	// JSON 编解码器
+	Codec Codec
+
+	// 请求中间件（按添加顺序由外到内执行）
+	Middlewares []Middleware
+
 	// 其他配置
 	EnableMetrics bool
+	Metrics       metrics.Metrics // 指标收集器，开启指标但未设置时使用内存收集器
 	EnableDebug   bool
+	Tracer        tracing.Tracer // 链路追踪，为空时不创建 span
 	// 连接池配置
 	MaxIdleConns        int           // 最大空闲连接
 	MaxIdleConnsPerHost int           // 每个主机的最大空闲连接数
@@ -36,22 +80,68 @@ type Config struct {
 // DefaultConfig 返回默认配置
 func DefaultConfig() *Config {
 	return &Config{
-		Addresses:           []string{"http://localhost:9200"},
-		MaxRetries:          3,
-		RetryBackoff:        time.Second,
-		Timeout:             30 * time.Second,
-		EnableMetrics:       false,
-		EnableDebug:         false,
-		MaxIdleConns:        100,
-		MaxIdleConnsPerHost: 10,
-		MaxConnsPerHost:     0,
-		IdleConnTimeout:     90 * time.Second,
+		Addresses:            []string{"http://localhost:9200"},
+		MaxRetries:           3,
+		RetryBackoff:         time.Second,
+		ResurrectTimeout:     time.Minute,
+		ResurrectMaxDelay:    30 * time.Minute,
+		SniffTimeout:         5 * time.Second,
+		Timeout:              30 * time.Second,
+		CompressionLevel:     gzip.DefaultCompression,
+		CompressionThreshold: DefaultCompressionThreshold,
+		Codec:                JSONCodec{},
+		EnableMetrics:        false,
+		EnableDebug:          false,
+		MaxIdleConns:         100,
+		MaxIdleConnsPerHost:  10,
+		MaxConnsPerHost:      0,
+		IdleConnTimeout:      90 * time.Second,
 	}
 }
 
+// DefaultCompressionThreshold 默认压缩阈值，小请求压缩收益不大
+const DefaultCompressionThreshold = 1024
+
 // Option 配置选项函数
 type Option func(*Config)
 
+// RoundTripFunc 执行单次 HTTP 请求的函数
+type RoundTripFunc func(req *http.Request) (*http.Response, error)
+
+// Middleware 请求中间件，包装下一个 RoundTripFunc
+// 可用于注入请求头、记录耗时、改写请求或在测试中直接返回响应
+type Middleware func(next RoundTripFunc) RoundTripFunc
+
+// NodeFilter 嗅探节点过滤函数
+// roles 为节点角色（如 master、data、ingest），attributes 为节点自定义属性
+type NodeFilter func(roles []string, attributes map[string]string) bool
+
+// ExcludeDedicatedMasters 排除专用主节点（只有 master 角色的节点）
+func ExcludeDedicatedMasters() NodeFilter {
+	return func(roles []string, attributes map[string]string) bool {
+		return !(len(roles) == 1 && roles[0] == "master")
+	}
+}
+
+// RequireRoles 只保留同时具备指定角色的节点
+func RequireRoles(required ...string) NodeFilter {
+	return func(roles []string, attributes map[string]string) bool {
+		for _, r := range required {
+			found := false
+			for _, role := range roles {
+				if role == r {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+}
+
 // WithTransport 设置传输层
 func WithTransport(skip bool) Option {
 	return func(c *Config) {
@@ -66,7 +156,7 @@ func WithAddresses(addresses ...string) Option {
 	}
 }
 
-// WithAuth 设置认证信息
+// WithAuth 设置认证信息（Basic 认证）
 func WithAuth(username, password string) Option {
 	return func(c *Config) {
 		c.Username = username
@@ -81,7 +171,25 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithCompression 开启 gzip 压缩
+// 请求体达到 CompressionThreshold（默认 1KB）时压缩并设置 Content-Encoding，同时请求并自动解压 gzip 响应
+// level 取值同 compress/gzip，如 gzip.BestSpeed、gzip.DefaultCompression
+func WithCompression(level int) Option {
+	return func(c *Config) {
+		c.CompressRequestBody = true
+		c.CompressionLevel = level
+	}
+}
+
+// WithCompressionThreshold 设置请求体压缩阈值（字节）
+func WithCompressionThreshold(threshold int) Option {
+	return func(c *Config) {
+		c.CompressionThreshold = threshold
+	}
+}
+
 // WithRetry 设置重试配置
+// 使用指数退避策略，backoff 为首次重试前的等待时间
 func WithRetry(maxRetries int, backoff time.Duration) Option {
 	return func(c *Config) {
 		c.MaxRetries = maxRetries
@@ -89,6 +197,53 @@ func WithRetry(maxRetries int, backoff time.Duration) Option {
 	}
 }
 
+// WithRetryPolicy 设置自定义重试策略
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Config) {
+		c.RetryPolicy = policy
+	}
+}
+
+// WithResurrectTimeout 设置死节点复活等待时间
+// initial 为首次标记死亡后的等待时间，之后每次连续失败翻倍，最长不超过 max
+func WithResurrectTimeout(initial, max time.Duration) Option {
+	return func(c *Config) {
+		c.ResurrectTimeout = initial
+		c.ResurrectMaxDelay = max
+	}
+}
+
+// WithSniff 启用节点嗅探
+// 启动时通过 /_nodes/http 发现集群节点，interval > 0 时按间隔定时刷新连接池
+func WithSniff(interval time.Duration) Option {
+	return func(c *Config) {
+		c.SniffOnStart = true
+		c.SniffInterval = interval
+	}
+}
+
+// WithSniffTimeout 设置单次嗅探超时时间
+func WithSniffTimeout(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.SniffTimeout = timeout
+	}
+}
+
+// WithSniffFilter 设置嗅探节点过滤函数
+func WithSniffFilter(filter NodeFilter) Option {
+	return func(c *Config) {
+		c.SniffFilter = filter
+	}
+}
+
+// WithMiddleware 添加请求中间件
+// 多个中间件按添加顺序由外到内执行，每次请求尝试（包括重试）都会经过中间件链
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(c *Config) {
+		c.Middlewares = append(c.Middlewares, middlewares...)
+	}
+}
+
 // WithDebug 启用调试模式
 func WithDebug(enable bool) Option {
 	return func(c *Config) {
@@ -96,6 +251,50 @@ func WithDebug(enable bool) Option {
 	}
 }
 
+// WithLogger 设置结构化日志
+// 请求开始/结束事件输出为 Debug 级别（Debug 级别时附带请求和响应体），重试和失败输出为 Warn 级别
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Config) {
+		c.Logger = logger
+	}
+}
+
+// WithSlowRequestThreshold 设置慢请求阈值
+func WithSlowRequestThreshold(threshold time.Duration) Option {
+	return func(c *Config) {
+		c.SlowRequestThreshold = threshold
+	}
+}
+
+// WithLogRedact 设置日志脱敏字段，请求/响应体中这些字段的值会被替换为 [REDACTED]
+func WithLogRedact(fields ...string) Option {
+	return func(c *Config) {
+		c.LogRedactFields = append(c.LogRedactFields, fields...)
+	}
+}
+
+// WithMetrics 开启或关闭指标收集（默认使用内存收集器，通过 Client.Metrics().Snapshot() 读取）
+func WithMetrics(enable bool) Option {
+	return func(c *Config) {
+		c.EnableMetrics = enable
+	}
+}
+
+// WithMetricsCollector 设置自定义指标收集器并开启指标收集
+func WithMetricsCollector(m metrics.Metrics) Option {
+	return func(c *Config) {
+		c.EnableMetrics = true
+		c.Metrics = m
+	}
+}
+
+// WithTracer 设置链路追踪，每次请求都会创建一个 span 并向 ES 传播 traceparent
+func WithTracer(tracer tracing.Tracer) Option {
+	return func(c *Config) {
+		c.Tracer = tracer
+	}
+}
+
 // WithMaxIdConns 设置最大空闲连接数
 func WithMaxIdConns(maxIdleConns int) Option {
 	return func(c *Config) {
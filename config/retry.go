@@ -0,0 +1,118 @@
+package config
+
+import (
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy 重试策略
+type RetryPolicy interface {
+	// ShouldRetry 判断第 attempt 次请求（从 1 开始）失败后是否重试
+	// 传输层错误时 resp 为 nil，收到错误状态码时 err 为 nil
+	ShouldRetry(attempt int, resp *http.Response, err error) bool
+
+	// Backoff 返回第 attempt 次请求失败后、下一次重试前的等待时间
+	Backoff(attempt int, resp *http.Response) time.Duration
+}
+
+// DefaultRetryStatusCodes 默认重试的状态码
+var DefaultRetryStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// ExponentialRetryPolicy 指数退避重试策略（带随机抖动）
+type ExponentialRetryPolicy struct {
+	MaxRetries        int           // 最大重试次数
+	InitialBackoff    time.Duration // 首次重试等待时间
+	MaxBackoff        time.Duration // 最大等待时间
+	Jitter            float64       // 抖动比例（0~1），等待时间在 [backoff*(1-Jitter), backoff] 之间随机
+	RetryStatusCodes  []int         // 需要重试的状态码
+	RespectRetryAfter bool          // 是否遵循响应的 Retry-After 头
+}
+
+// NewExponentialRetryPolicy 创建指数退避重试策略
+func NewExponentialRetryPolicy(maxRetries int, initialBackoff time.Duration) *ExponentialRetryPolicy {
+	return &ExponentialRetryPolicy{
+		MaxRetries:        maxRetries,
+		InitialBackoff:    initialBackoff,
+		MaxBackoff:        30 * time.Second,
+		Jitter:            0.5,
+		RetryStatusCodes:  DefaultRetryStatusCodes,
+		RespectRetryAfter: true,
+	}
+}
+
+// ShouldRetry 实现 RetryPolicy
+func (p *ExponentialRetryPolicy) ShouldRetry(attempt int, resp *http.Response, err error) bool {
+	if attempt > p.MaxRetries {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	if resp == nil {
+		return false
+	}
+	for _, code := range p.RetryStatusCodes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Backoff 实现 RetryPolicy
+func (p *ExponentialRetryPolicy) Backoff(attempt int, resp *http.Response) time.Duration {
+	if p.RespectRetryAfter && resp != nil {
+		if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+				return p.MaxBackoff
+			}
+			return wait
+		}
+	}
+
+	backoff := p.InitialBackoff
+	for i := 1; i < attempt; i++ {
+		backoff *= 2
+		if p.MaxBackoff > 0 && backoff >= p.MaxBackoff {
+			break
+		}
+	}
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+
+	if p.Jitter > 0 && backoff > 0 {
+		jitter := p.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+		backoff -= time.Duration(rand.Float64() * jitter * float64(backoff))
+	}
+
+	return backoff
+}
+
+// parseRetryAfter 解析 Retry-After 头（秒数或 HTTP 日期）
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		wait := time.Until(t)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
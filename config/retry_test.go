@@ -0,0 +1,142 @@
+package config
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// responseWith 创建指定状态码和 Retry-After 头的响应
+func responseWith(status int, retryAfter string) *http.Response {
+	resp := &http.Response{StatusCode: status, Header: http.Header{}}
+	if retryAfter != "" {
+		resp.Header.Set("Retry-After", retryAfter)
+	}
+	return resp
+}
+
+func TestExponentialRetryPolicy_ShouldRetry(t *testing.T) {
+	p := NewExponentialRetryPolicy(2, time.Second)
+	transportErr := errors.New("connection refused")
+
+	tests := []struct {
+		name    string
+		attempt int
+		resp    *http.Response
+		err     error
+		want    bool
+	}{
+		{"传输层错误", 1, nil, transportErr, true},
+		{"429", 1, responseWith(http.StatusTooManyRequests, ""), nil, true},
+		{"502", 1, responseWith(http.StatusBadGateway, ""), nil, true},
+		{"503", 2, responseWith(http.StatusServiceUnavailable, ""), nil, true},
+		{"504", 1, responseWith(http.StatusGatewayTimeout, ""), nil, true},
+		{"400 不重试", 1, responseWith(http.StatusBadRequest, ""), nil, false},
+		{"404 不重试", 1, responseWith(http.StatusNotFound, ""), nil, false},
+		{"500 不重试", 1, responseWith(http.StatusInternalServerError, ""), nil, false},
+		{"超过最大重试次数", 3, nil, transportErr, false},
+		{"没有响应也没有错误", 1, nil, nil, false},
+	}
+
+	for _, tt := range tests {
+		if got := p.ShouldRetry(tt.attempt, tt.resp, tt.err); got != tt.want {
+			t.Errorf("%s: ShouldRetry = %v，期望 %v", tt.name, got, tt.want)
+		}
+	}
+
+	// 自定义重试状态码
+	p.RetryStatusCodes = []int{http.StatusInternalServerError}
+	if !p.ShouldRetry(1, responseWith(http.StatusInternalServerError, ""), nil) {
+		t.Error("自定义状态码 500 应该重试")
+	}
+	if p.ShouldRetry(1, responseWith(http.StatusServiceUnavailable, ""), nil) {
+		t.Error("不在自定义状态码中的 503 不应该重试")
+	}
+}
+
+func TestExponentialRetryPolicy_Backoff(t *testing.T) {
+	p := NewExponentialRetryPolicy(10, 100*time.Millisecond)
+	p.MaxBackoff = time.Second
+	p.Jitter = 0
+
+	// 不带抖动时按指数增长，不超过上限
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, w := range want {
+		if got := p.Backoff(i+1, nil); got != w {
+			t.Errorf("第 %d 次重试的等待时间 = %v，期望 %v", i+1, got, w)
+		}
+	}
+
+	// 带抖动时等待时间在 [backoff*(1-Jitter), backoff] 之间
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		got := p.Backoff(3, nil)
+		if got < 200*time.Millisecond || got > 400*time.Millisecond {
+			t.Fatalf("带抖动的等待时间应该在 [200ms, 400ms] 之间，实际 %v", got)
+		}
+	}
+
+	// 抖动比例超过 1 时按 1 处理，等待时间不会为负数
+	p.Jitter = 2
+	for i := 0; i < 100; i++ {
+		if got := p.Backoff(1, nil); got < 0 || got > 100*time.Millisecond {
+			t.Fatalf("等待时间应该在 [0, 100ms] 之间，实际 %v", got)
+		}
+	}
+}
+
+func TestExponentialRetryPolicy_RetryAfter(t *testing.T) {
+	p := NewExponentialRetryPolicy(3, 100*time.Millisecond)
+	p.MaxBackoff = 10 * time.Second
+	p.Jitter = 0
+
+	// 秒数
+	if got := p.Backoff(1, responseWith(http.StatusTooManyRequests, "3")); got != 3*time.Second {
+		t.Errorf("Retry-After: 3 应该等待 3s，实际 %v", got)
+	}
+
+	// 超过上限时使用 MaxBackoff
+	if got := p.Backoff(1, responseWith(http.StatusTooManyRequests, "60")); got != 10*time.Second {
+		t.Errorf("Retry-After 超过上限时应该等待 10s，实际 %v", got)
+	}
+
+	// HTTP 日期
+	date := time.Now().Add(5 * time.Second).UTC().Format(http.TimeFormat)
+	if got := p.Backoff(1, responseWith(http.StatusServiceUnavailable, date)); got < 3*time.Second || got > 5*time.Second {
+		t.Errorf("Retry-After 为 5 秒后的日期时应该等待约 5s，实际 %v", got)
+	}
+
+	// 无法解析时使用指数退避
+	if got := p.Backoff(1, responseWith(http.StatusServiceUnavailable, "soon")); got != 100*time.Millisecond {
+		t.Errorf("无法解析的 Retry-After 应该使用指数退避，实际 %v", got)
+	}
+
+	// 关闭 RespectRetryAfter
+	p.RespectRetryAfter = false
+	if got := p.Backoff(1, responseWith(http.StatusTooManyRequests, "3")); got != 100*time.Millisecond {
+		t.Errorf("不遵循 Retry-After 时应该使用指数退避，实际 %v", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{"", 0, false},
+		{"0", 0, true},
+		{"120", 2 * time.Minute, true},
+		{"-1", 0, false},
+		{"abc", 0, false},
+		{time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, true}, // 过去的日期不等待
+	}
+
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseRetryAfter(%q) = (%v, %v)，期望 (%v, %v)", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package config
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// redactedValue 脱敏后的占位值
+const redactedValue = "[REDACTED]"
+
+// Validate 校验配置，返回所有问题合并后的错误
+func (c *Config) Validate() error {
+	var errs []error
+
+	if len(c.Addresses) == 0 {
+		errs = append(errs, fmt.Errorf("至少需要配置一个ES地址"))
+	}
+	for _, address := range c.Addresses {
+		if err := validateAddress(address); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	durations := []struct {
+		name  string
+		value time.Duration
+	}{
+		{"Timeout", c.Timeout},
+		{"RetryBackoff", c.RetryBackoff},
+		{"ResurrectTimeout", c.ResurrectTimeout},
+		{"ResurrectMaxDelay", c.ResurrectMaxDelay},
+		{"SniffInterval", c.SniffInterval},
+		{"SniffTimeout", c.SniffTimeout},
+		{"SlowRequestThreshold", c.SlowRequestThreshold},
+		{"IdleConnTimeout", c.IdleConnTimeout},
+	}
+	for _, d := range durations {
+		if d.value < 0 {
+			errs = append(errs, fmt.Errorf("%s 不能为负数: %v", d.name, d.value))
+		}
+	}
+
+	counts := []struct {
+		name  string
+		value int
+	}{
+		{"MaxRetries", c.MaxRetries},
+		{"MaxIdleConns", c.MaxIdleConns},
+		{"MaxIdleConnsPerHost", c.MaxIdleConnsPerHost},
+		{"MaxConnsPerHost", c.MaxConnsPerHost},
+		{"CompressionThreshold", c.CompressionThreshold},
+	}
+	for _, n := range counts {
+		if n.value < 0 {
+			errs = append(errs, fmt.Errorf("%s 不能为负数: %d", n.name, n.value))
+		}
+	}
+
+	if c.ResurrectMaxDelay > 0 && c.ResurrectMaxDelay < c.ResurrectTimeout {
+		errs = append(errs, fmt.Errorf("ResurrectMaxDelay(%v) 不能小于 ResurrectTimeout(%v)", c.ResurrectMaxDelay, c.ResurrectTimeout))
+	}
+
+	if c.CompressRequestBody && (c.CompressionLevel < gzip.HuffmanOnly || c.CompressionLevel > gzip.BestCompression) {
+		errs = append(errs, fmt.Errorf("CompressionLevel 无效: %d", c.CompressionLevel))
+	}
+
+	errs = append(errs, c.validateCredentials()...)
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("配置无效: %w", err)
+	}
+	return nil
+}
+
+// validateCredentials 校验认证方式组合
+func (c *Config) validateCredentials() []error {
+	var errs []error
+
+	if c.Password != "" && c.Username == "" {
+		errs = append(errs, fmt.Errorf("设置了密码但缺少用户名"))
+	}
+
+	methods := make([]string, 0)
+	if c.Username != "" {
+		methods = append(methods, "Basic")
+	}
+	if c.APIKey != "" {
+		methods = append(methods, "APIKey")
+	}
+	if c.BearerToken != "" {
+		methods = append(methods, "BearerToken")
+	}
+	if c.CredentialsProvider != nil {
+		methods = append(methods, "CredentialsProvider")
+	}
+	if len(methods) > 1 {
+		errs = append(errs, fmt.Errorf("只能使用一种认证方式，当前同时配置了 %v", methods))
+	}
+
+	if (len(c.ClientCert) > 0) != (len(c.ClientKey) > 0) {
+		errs = append(errs, fmt.Errorf("客户端证书和私钥必须同时配置"))
+	}
+
+	return errs
+}
+
+// validateAddress 校验单个 ES 地址
+func validateAddress(address string) error {
+	u, err := url.Parse(address)
+	if err != nil {
+		return fmt.Errorf("ES地址格式错误: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("ES地址 %q 必须以 http:// 或 https:// 开头", address)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("ES地址 %q 缺少主机名", address)
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return fmt.Errorf("ES地址 %q 不能包含查询参数", address)
+	}
+	return nil
+}
+
+// Redacted 返回配置的副本，密码、API Key、Token、私钥等敏感信息被替换为占位值
+// 切片字段会被复制，修改副本不会影响原配置
+func (c *Config) Redacted() Config {
+	snapshot := *c
+
+	snapshot.Addresses = make([]string, len(c.Addresses))
+	for i, address := range c.Addresses {
+		if u, err := url.Parse(address); err == nil {
+			address = u.Redacted()
+		}
+		snapshot.Addresses[i] = address
+	}
+	snapshot.LogRedactFields = slices.Clone(c.LogRedactFields)
+	snapshot.Middlewares = slices.Clone(c.Middlewares)
+	snapshot.CACert = slices.Clone(c.CACert)
+	snapshot.ClientCert = slices.Clone(c.ClientCert)
+
+	if c.Password != "" {
+		snapshot.Password = redactedValue
+	}
+	if c.APIKey != "" {
+		snapshot.APIKey = redactedValue
+	}
+	if c.BearerToken != "" {
+		snapshot.BearerToken = redactedValue
+	}
+	if len(c.ClientKey) > 0 {
+		snapshot.ClientKey = []byte(redactedValue)
+	}
+
+	return snapshot
+}
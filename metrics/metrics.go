@@ -0,0 +1,258 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Metrics 指标收集接口
+type Metrics interface {
+	// ObserveRequest 记录一次请求尝试（包括重试）
+	ObserveRequest(endpoint string, status int, duration time.Duration, bytesSent, bytesReceived int64)
+
+	// ObserveError 记录一次失败的请求，errorType 为 ESError.Type 或传输层错误类型
+	ObserveError(endpoint, errorType string)
+
+	// ObserveRetry 记录一次重试
+	ObserveRetry(endpoint string)
+
+	// ObserveBulkItems 记录批量操作单项的成功/失败数量
+	ObserveBulkItems(succeeded, failed int)
+
+	// Snapshot 返回当前指标快照
+	Snapshot() Snapshot
+}
+
+// DefaultLatencyBuckets 默认延迟直方图桶上限
+var DefaultLatencyBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// Snapshot 指标快照
+type Snapshot struct {
+	Endpoints          map[string]EndpointSnapshot `json:"endpoints"`
+	Errors             map[string]int64            `json:"errors"` // 按错误类型统计
+	Retries            int64                       `json:"retries"`
+	BytesSent          int64                       `json:"bytes_sent"`
+	BytesReceived      int64                       `json:"bytes_received"`
+	BulkItemsSucceeded int64                       `json:"bulk_items_succeeded"`
+	BulkItemsFailed    int64                       `json:"bulk_items_failed"`
+}
+
+// EndpointSnapshot 单个接口的指标快照
+type EndpointSnapshot struct {
+	Requests int64             `json:"requests"`
+	Errors   int64             `json:"errors"`
+	Retries  int64             `json:"retries"`
+	Statuses map[int]int64     `json:"statuses"`
+	Latency  HistogramSnapshot `json:"latency"`
+}
+
+// HistogramSnapshot 延迟直方图快照
+type HistogramSnapshot struct {
+	Buckets []time.Duration `json:"buckets"` // 桶上限
+	Counts  []int64         `json:"counts"`  // 每个桶的计数，最后一个为超出所有上限的计数
+	Count   int64           `json:"count"`
+	Sum     time.Duration   `json:"sum"`
+}
+
+// Mean 平均延迟
+func (h HistogramSnapshot) Mean() time.Duration {
+	if h.Count == 0 {
+		return 0
+	}
+	return h.Sum / time.Duration(h.Count)
+}
+
+// Quantile 根据桶估算分位数（返回所在桶的上限，超出所有桶时返回最大上限）
+func (h HistogramSnapshot) Quantile(q float64) time.Duration {
+	if h.Count == 0 || len(h.Buckets) == 0 {
+		return 0
+	}
+	target := int64(math.Ceil(q * float64(h.Count)))
+	if target < 1 {
+		target = 1
+	}
+	var cumulative int64
+	for i, c := range h.Counts {
+		cumulative += c
+		if cumulative >= target && i < len(h.Buckets) {
+			return h.Buckets[i]
+		}
+	}
+	return h.Buckets[len(h.Buckets)-1]
+}
+
+// Names 返回按字母排序的接口名称
+func (s Snapshot) Names() []string {
+	names := make([]string, 0, len(s.Endpoints))
+	for name := range s.Endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// InMemory 内存指标收集器（并发安全）
+type InMemory struct {
+	mu                 sync.Mutex
+	buckets            []time.Duration
+	endpoints          map[string]*endpointMetrics
+	errors             map[string]int64
+	retries            int64
+	bytesSent          int64
+	bytesReceived      int64
+	bulkItemsSucceeded int64
+	bulkItemsFailed    int64
+}
+
+// endpointMetrics 单个接口的指标
+type endpointMetrics struct {
+	requests int64
+	errors   int64
+	retries  int64
+	statuses map[int]int64
+	counts   []int64
+	count    int64
+	sum      time.Duration
+}
+
+// NewInMemory 创建内存指标收集器
+// buckets 为空时使用 DefaultLatencyBuckets
+func NewInMemory(buckets ...time.Duration) *InMemory {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	sorted := append([]time.Duration(nil), buckets...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return &InMemory{
+		buckets:   sorted,
+		endpoints: make(map[string]*endpointMetrics),
+		errors:    make(map[string]int64),
+	}
+}
+
+// endpoint 获取或创建接口指标（调用方需持有锁）
+func (m *InMemory) endpoint(name string) *endpointMetrics {
+	e, ok := m.endpoints[name]
+	if !ok {
+		e = &endpointMetrics{
+			statuses: make(map[int]int64),
+			counts:   make([]int64, len(m.buckets)+1),
+		}
+		m.endpoints[name] = e
+	}
+	return e
+}
+
+// ObserveRequest 实现 Metrics
+func (m *InMemory) ObserveRequest(endpoint string, status int, duration time.Duration, bytesSent, bytesReceived int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := m.endpoint(endpoint)
+	e.requests++
+	if status > 0 {
+		e.statuses[status]++
+	}
+	e.count++
+	e.sum += duration
+
+	idx := sort.Search(len(m.buckets), func(i int) bool { return duration <= m.buckets[i] })
+	e.counts[idx]++
+
+	m.bytesSent += bytesSent
+	m.bytesReceived += bytesReceived
+}
+
+// ObserveError 实现 Metrics
+func (m *InMemory) ObserveError(endpoint, errorType string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.endpoint(endpoint).errors++
+	m.errors[errorType]++
+}
+
+// ObserveRetry 实现 Metrics
+func (m *InMemory) ObserveRetry(endpoint string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.endpoint(endpoint).retries++
+	m.retries++
+}
+
+// ObserveBulkItems 实现 Metrics
+func (m *InMemory) ObserveBulkItems(succeeded, failed int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.bulkItemsSucceeded += int64(succeeded)
+	m.bulkItemsFailed += int64(failed)
+}
+
+// Snapshot 实现 Metrics
+func (m *InMemory) Snapshot() Snapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := Snapshot{
+		Endpoints:          make(map[string]EndpointSnapshot, len(m.endpoints)),
+		Errors:             make(map[string]int64, len(m.errors)),
+		Retries:            m.retries,
+		BytesSent:          m.bytesSent,
+		BytesReceived:      m.bytesReceived,
+		BulkItemsSucceeded: m.bulkItemsSucceeded,
+		BulkItemsFailed:    m.bulkItemsFailed,
+	}
+	for k, v := range m.errors {
+		snapshot.Errors[k] = v
+	}
+	for name, e := range m.endpoints {
+		statuses := make(map[int]int64, len(e.statuses))
+		for k, v := range e.statuses {
+			statuses[k] = v
+		}
+		snapshot.Endpoints[name] = EndpointSnapshot{
+			Requests: e.requests,
+			Errors:   e.errors,
+			Retries:  e.retries,
+			Statuses: statuses,
+			Latency: HistogramSnapshot{
+				Buckets: append([]time.Duration(nil), m.buckets...),
+				Counts:  append([]int64(nil), e.counts...),
+				Count:   e.count,
+				Sum:     e.sum,
+			},
+		}
+	}
+	return snapshot
+}
+
+// Reset 清空所有指标
+func (m *InMemory) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.endpoints = make(map[string]*endpointMetrics)
+	m.errors = make(map[string]int64)
+	m.retries = 0
+	m.bytesSent = 0
+	m.bytesReceived = 0
+	m.bulkItemsSucceeded = 0
+	m.bulkItemsFailed = 0
+}
@@ -0,0 +1,140 @@
+package metrics
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestInMemory_Observe(t *testing.T) {
+	m := NewInMemory(100*time.Millisecond, 10*time.Millisecond) // 未排序的桶
+
+	m.ObserveRequest("search", 200, 5*time.Millisecond, 10, 100)
+	m.ObserveRequest("search", 200, 50*time.Millisecond, 10, 100)
+	m.ObserveRequest("search", 503, time.Second, 10, 0)
+	m.ObserveRequest("bulk", 0, time.Millisecond, 20, 0) // 传输层错误没有状态码
+	m.ObserveError("search", "unavailable")
+	m.ObserveError("bulk", "transport_error")
+	m.ObserveRetry("search")
+	m.ObserveBulkItems(3, 1)
+	m.ObserveBulkItems(2, 0)
+
+	s := m.Snapshot()
+
+	if names := s.Names(); len(names) != 2 || names[0] != "bulk" || names[1] != "search" {
+		t.Fatalf("接口名称应该排序，实际 %v", names)
+	}
+
+	search := s.Endpoints["search"]
+	if search.Requests != 3 || search.Errors != 1 || search.Retries != 1 {
+		t.Fatalf("search 的请求/错误/重试次数不正确: %+v", search)
+	}
+	if search.Statuses[200] != 2 || search.Statuses[503] != 1 {
+		t.Fatalf("search 的状态码统计不正确: %v", search.Statuses)
+	}
+	if len(s.Endpoints["bulk"].Statuses) != 0 {
+		t.Fatalf("没有状态码的请求不应该统计状态码: %v", s.Endpoints["bulk"].Statuses)
+	}
+
+	latency := search.Latency
+	if latency.Buckets[0] != 10*time.Millisecond || latency.Buckets[1] != 100*time.Millisecond {
+		t.Fatalf("直方图的桶应该按上限排序，实际 %v", latency.Buckets)
+	}
+	if latency.Counts[0] != 1 || latency.Counts[1] != 1 || latency.Counts[2] != 1 {
+		t.Fatalf("直方图计数不正确: %v", latency.Counts)
+	}
+	if latency.Count != 3 || latency.Sum != 1055*time.Millisecond {
+		t.Fatalf("直方图总数/总和不正确: %d、%v", latency.Count, latency.Sum)
+	}
+
+	if s.Errors["unavailable"] != 1 || s.Errors["transport_error"] != 1 {
+		t.Fatalf("错误类型统计不正确: %v", s.Errors)
+	}
+	if s.Retries != 1 || s.BytesSent != 50 || s.BytesReceived != 200 {
+		t.Fatalf("重试/字节数统计不正确: %+v", s)
+	}
+	if s.BulkItemsSucceeded != 5 || s.BulkItemsFailed != 1 {
+		t.Fatalf("批量操作单项统计不正确: %d、%d", s.BulkItemsSucceeded, s.BulkItemsFailed)
+	}
+
+	t.Logf("✓ 内存指标收集")
+}
+
+func TestInMemory_SnapshotIsCopy(t *testing.T) {
+	m := NewInMemory()
+	m.ObserveRequest("search", 200, time.Millisecond, 0, 0)
+
+	s := m.Snapshot()
+	s.Endpoints["search"].Statuses[200] = 100
+	s.Endpoints["search"].Latency.Counts[0] = 100
+	s.Errors["x"] = 1
+
+	again := m.Snapshot()
+	if again.Endpoints["search"].Statuses[200] != 1 || again.Endpoints["search"].Latency.Counts[0] != 1 || len(again.Errors) != 0 {
+		t.Fatal("修改快照不应该影响收集器")
+	}
+
+	m.Reset()
+	if s := m.Snapshot(); len(s.Endpoints) != 0 || s.Retries != 0 || s.BytesSent != 0 {
+		t.Fatalf("Reset 后应该清空所有指标: %+v", s)
+	}
+
+	t.Logf("✓ 快照与收集器相互独立")
+}
+
+func TestHistogramSnapshot_Quantile(t *testing.T) {
+	h := HistogramSnapshot{
+		Buckets: []time.Duration{10 * time.Millisecond, 100 * time.Millisecond, time.Second},
+		Counts:  []int64{50, 40, 9, 1},
+		Count:   100,
+		Sum:     10 * time.Second,
+	}
+
+	tests := []struct {
+		q    float64
+		want time.Duration
+	}{
+		{0, 10 * time.Millisecond},
+		{0.5, 10 * time.Millisecond},
+		{0.9, 100 * time.Millisecond},
+		{0.99, time.Second},
+		{1, time.Second}, // 超出所有桶时返回最大上限
+	}
+	for _, tt := range tests {
+		if got := h.Quantile(tt.q); got != tt.want {
+			t.Errorf("Quantile(%v) = %v，期望 %v", tt.q, got, tt.want)
+		}
+	}
+
+	if got := h.Mean(); got != 100*time.Millisecond {
+		t.Errorf("Mean = %v，期望 100ms", got)
+	}
+	if (HistogramSnapshot{}).Quantile(0.5) != 0 || (HistogramSnapshot{}).Mean() != 0 {
+		t.Error("空直方图应该返回 0")
+	}
+}
+
+func TestInMemory_Concurrent(t *testing.T) {
+	m := NewInMemory()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				m.ObserveRequest("search", 200, time.Millisecond, 1, 1)
+				m.ObserveRetry("search")
+				m.Snapshot()
+			}
+		}()
+	}
+	wg.Wait()
+
+	s := m.Snapshot()
+	if s.Endpoints["search"].Requests != 1000 || s.Retries != 1000 {
+		t.Fatalf("并发统计不正确: 请求 %d，重试 %d", s.Endpoints["search"].Requests, s.Retries)
+	}
+
+	t.Logf("✓ 并发安全")
+}
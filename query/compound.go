@@ -0,0 +1,111 @@
+package query
+
+// ========== nested ==========
+
+// NestedQuery 嵌套查询（用于 nested 类型字段）
+type NestedQuery struct {
+	path   string
+	query  Query
+	params map[string]interface{}
+}
+
+// Nested 创建嵌套查询
+//
+//	query.Nested("comments", query.Bool().
+//	    Filter(query.Term("comments.author", "alice")).
+//	    Filter(query.Range("comments.likes").Gte(10)))
+func Nested(path string, q Query) *NestedQuery {
+	return &NestedQuery{path: path, query: q, params: make(map[string]interface{})}
+}
+
+// ScoreMode 设置子文档评分的合并方式（"avg"、"max"、"min"、"sum"、"none"）
+func (q *NestedQuery) ScoreMode(mode string) *NestedQuery {
+	q.params["score_mode"] = mode
+	return q
+}
+
+// IgnoreUnmapped 字段未映射时不报错
+func (q *NestedQuery) IgnoreUnmapped() *NestedQuery {
+	q.params["ignore_unmapped"] = true
+	return q
+}
+
+// InnerHits 返回匹配的子文档，options 为 inner_hits 参数（如 size、_source），可以为 nil
+func (q *NestedQuery) InnerHits(options map[string]interface{}) *NestedQuery {
+	if options == nil {
+		options = map[string]interface{}{}
+	}
+	q.params["inner_hits"] = options
+	return q
+}
+
+// Build 实现 Query
+func (q *NestedQuery) Build() map[string]interface{} {
+	body := copyParams(q.params)
+	body["path"] = q.path
+	if q.query != nil {
+		body["query"] = q.query.Build()
+	}
+	return map[string]interface{}{"nested": body}
+}
+
+// ========== constant_score ==========
+
+// ConstantScoreQuery 固定评分查询
+type ConstantScoreQuery struct {
+	filter Query
+	boost  *float64
+}
+
+// ConstantScore 创建固定评分查询，所有匹配文档的评分都等于 boost（默认 1）
+func ConstantScore(filter Query) *ConstantScoreQuery {
+	return &ConstantScoreQuery{filter: filter}
+}
+
+// Boost 设置评分
+func (q *ConstantScoreQuery) Boost(boost float64) *ConstantScoreQuery {
+	q.boost = &boost
+	return q
+}
+
+// Build 实现 Query
+func (q *ConstantScoreQuery) Build() map[string]interface{} {
+	body := map[string]interface{}{"filter": q.filter.Build()}
+	if q.boost != nil {
+		body["boost"] = *q.boost
+	}
+	return map[string]interface{}{"constant_score": body}
+}
+
+// ========== 地理位置 ==========
+
+// GeoDistance 创建地理距离查询，distance 如 "10km"
+func GeoDistance(field string, lat, lon float64, distance string) Query {
+	return Raw(map[string]interface{}{
+		"geo_distance": map[string]interface{}{
+			"distance": distance,
+			field: map[string]interface{}{
+				"lat": lat,
+				"lon": lon,
+			},
+		},
+	})
+}
+
+// GeoBoundingBox 创建地理边界框查询
+func GeoBoundingBox(field string, topLat, topLon, bottomLat, bottomLon float64) Query {
+	return Raw(map[string]interface{}{
+		"geo_bounding_box": map[string]interface{}{
+			field: map[string]interface{}{
+				"top_left": map[string]interface{}{
+					"lat": topLat,
+					"lon": topLon,
+				},
+				"bottom_right": map[string]interface{}{
+					"lat": bottomLat,
+					"lon": bottomLon,
+				},
+			},
+		},
+	})
+}
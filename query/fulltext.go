@@ -0,0 +1,154 @@
+package query
+
+// ========== match ==========
+
+// MatchQuery 全文匹配查询
+type MatchQuery struct {
+	fieldQuery
+}
+
+// Match 创建 match 查询
+func Match(field string, value interface{}) *MatchQuery {
+	return &MatchQuery{newFieldQuery("match", field, "query", value)}
+}
+
+// Operator 设置分词后各词项的组合方式（"and" 或 "or"，默认 "or"）
+func (q *MatchQuery) Operator(operator string) *MatchQuery {
+	q.set("operator", operator)
+	return q
+}
+
+// Fuzziness 设置模糊匹配的编辑距离，如 "AUTO"
+func (q *MatchQuery) Fuzziness(fuzziness interface{}) *MatchQuery {
+	q.set("fuzziness", fuzziness)
+	return q
+}
+
+// Analyzer 设置查询使用的分析器
+func (q *MatchQuery) Analyzer(analyzer string) *MatchQuery {
+	q.set("analyzer", analyzer)
+	return q
+}
+
+// MinimumShouldMatch 设置最少匹配词项数量
+func (q *MatchQuery) MinimumShouldMatch(value interface{}) *MatchQuery {
+	q.set("minimum_should_match", value)
+	return q
+}
+
+// Boost 设置权重
+func (q *MatchQuery) Boost(boost float64) *MatchQuery {
+	q.set("boost", boost)
+	return q
+}
+
+// ========== match_phrase ==========
+
+// MatchPhraseQuery 短语匹配查询
+type MatchPhraseQuery struct {
+	fieldQuery
+}
+
+// MatchPhrase 创建短语匹配查询
+func MatchPhrase(field string, value interface{}) *MatchPhraseQuery {
+	return &MatchPhraseQuery{newFieldQuery("match_phrase", field, "query", value)}
+}
+
+// Slop 设置词项之间允许的最大间隔
+func (q *MatchPhraseQuery) Slop(slop int) *MatchPhraseQuery {
+	q.set("slop", slop)
+	return q
+}
+
+// Analyzer 设置查询使用的分析器
+func (q *MatchPhraseQuery) Analyzer(analyzer string) *MatchPhraseQuery {
+	q.set("analyzer", analyzer)
+	return q
+}
+
+// Boost 设置权重
+func (q *MatchPhraseQuery) Boost(boost float64) *MatchPhraseQuery {
+	q.set("boost", boost)
+	return q
+}
+
+// ========== multi_match ==========
+
+// MultiMatchQuery 多字段匹配查询
+type MultiMatchQuery struct {
+	params map[string]interface{}
+}
+
+// MultiMatch 创建多字段匹配查询，字段可以带权重，如 "title^2"
+func MultiMatch(text string, fields ...string) *MultiMatchQuery {
+	params := map[string]interface{}{"query": text}
+	if len(fields) > 0 {
+		params["fields"] = fields
+	}
+	return &MultiMatchQuery{params: params}
+}
+
+// Type 设置匹配类型，如 "best_fields"、"most_fields"、"cross_fields"、"phrase"
+func (q *MultiMatchQuery) Type(matchType string) *MultiMatchQuery {
+	q.params["type"] = matchType
+	return q
+}
+
+// Operator 设置分词后各词项的组合方式（"and" 或 "or"）
+func (q *MultiMatchQuery) Operator(operator string) *MultiMatchQuery {
+	q.params["operator"] = operator
+	return q
+}
+
+// Boost 设置权重
+func (q *MultiMatchQuery) Boost(boost float64) *MultiMatchQuery {
+	q.params["boost"] = boost
+	return q
+}
+
+// Build 实现 Query
+func (q *MultiMatchQuery) Build() map[string]interface{} {
+	return map[string]interface{}{"multi_match": copyParams(q.params)}
+}
+
+// ========== query_string ==========
+
+// QueryStringQuery 查询字符串查询（支持 AND/OR/NOT、通配符等语法）
+type QueryStringQuery struct {
+	params map[string]interface{}
+}
+
+// QueryString 创建查询字符串查询
+func QueryString(text string, fields ...string) *QueryStringQuery {
+	params := map[string]interface{}{"query": text}
+	if len(fields) > 0 {
+		params["fields"] = fields
+	}
+	return &QueryStringQuery{params: params}
+}
+
+// DefaultOperator 设置默认组合方式（"AND" 或 "OR"）
+func (q *QueryStringQuery) DefaultOperator(operator string) *QueryStringQuery {
+	q.params["default_operator"] = operator
+	return q
+}
+
+// Analyzer 设置查询使用的分析器
+func (q *QueryStringQuery) Analyzer(analyzer string) *QueryStringQuery {
+	q.params["analyzer"] = analyzer
+	return q
+}
+
+// Build 实现 Query
+func (q *QueryStringQuery) Build() map[string]interface{} {
+	return map[string]interface{}{"query_string": copyParams(q.params)}
+}
+
+// copyParams 复制参数，避免 Build 的结果被后续链式调用修改
+func copyParams(params map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		copied[k] = v
+	}
+	return copied
+}
@@ -0,0 +1,159 @@
+// Package query 提供可组合的查询 DSL 节点
+//
+// 同一个查询可以传给 SearchBuilder、ScrollBuilder、SearchAfterBuilder、
+// UpdateByQueryBuilder、DeleteByQueryBuilder 等构建器的 Query 方法：
+//
+//	active := query.Bool().
+//	    Filter(query.Term("status", "active")).
+//	    Filter(query.Range("created_at").Gte("now-30d"))
+//
+//	builder.NewSearchBuilder(client, "orders").Query(active).Do(ctx)
+//	builder.NewSearchBuilder(client, "orders").Query(active).Count(ctx)
+//	builder.NewDeleteByQueryBuilder(client, "orders").Query(query.Bool().MustNot(active)).Do(ctx)
+package query
+
+// Query 查询节点
+type Query interface {
+	// Build 构建查询 DSL
+	Build() map[string]interface{}
+}
+
+// ========== bool 查询 ==========
+
+// BoolQuery bool 组合查询
+type BoolQuery struct {
+	must               []Query
+	filter             []Query
+	should             []Query
+	mustNot            []Query
+	minimumShouldMatch interface{}
+	boost              *float64
+}
+
+// Bool 创建 bool 查询
+func Bool() *BoolQuery {
+	return &BoolQuery{}
+}
+
+// Must 必须匹配（参与评分）
+func (q *BoolQuery) Must(queries ...Query) *BoolQuery {
+	q.must = append(q.must, queries...)
+	return q
+}
+
+// Filter 必须匹配（不参与评分，可缓存）
+func (q *BoolQuery) Filter(queries ...Query) *BoolQuery {
+	q.filter = append(q.filter, queries...)
+	return q
+}
+
+// Should 应该匹配
+func (q *BoolQuery) Should(queries ...Query) *BoolQuery {
+	q.should = append(q.should, queries...)
+	return q
+}
+
+// MustNot 必须不匹配
+func (q *BoolQuery) MustNot(queries ...Query) *BoolQuery {
+	q.mustNot = append(q.mustNot, queries...)
+	return q
+}
+
+// MinimumShouldMatch 设置最少匹配 should 条件数量，可以是整数或 "75%" 这样的字符串
+func (q *BoolQuery) MinimumShouldMatch(value interface{}) *BoolQuery {
+	q.minimumShouldMatch = value
+	return q
+}
+
+// Boost 设置权重
+func (q *BoolQuery) Boost(boost float64) *BoolQuery {
+	q.boost = &boost
+	return q
+}
+
+// IsEmpty 是否没有任何条件
+func (q *BoolQuery) IsEmpty() bool {
+	return len(q.must) == 0 && len(q.filter) == 0 && len(q.should) == 0 && len(q.mustNot) == 0
+}
+
+// Build 实现 Query
+func (q *BoolQuery) Build() map[string]interface{} {
+	body := make(map[string]interface{})
+	if len(q.must) > 0 {
+		body["must"] = buildAll(q.must)
+	}
+	if len(q.filter) > 0 {
+		body["filter"] = buildAll(q.filter)
+	}
+	if len(q.should) > 0 {
+		body["should"] = buildAll(q.should)
+	}
+	if len(q.mustNot) > 0 {
+		body["must_not"] = buildAll(q.mustNot)
+	}
+	if q.minimumShouldMatch != nil {
+		body["minimum_should_match"] = q.minimumShouldMatch
+	}
+	if q.boost != nil {
+		body["boost"] = *q.boost
+	}
+	return map[string]interface{}{"bool": body}
+}
+
+// buildAll 构建多个查询，忽略 nil
+func buildAll(queries []Query) []map[string]interface{} {
+	built := make([]map[string]interface{}, 0, len(queries))
+	for _, q := range queries {
+		if q != nil {
+			built = append(built, q.Build())
+		}
+	}
+	return built
+}
+
+// ========== 其他 ==========
+
+// MatchAllQuery 匹配所有文档
+type MatchAllQuery struct {
+	boost *float64
+}
+
+// MatchAll 创建 match_all 查询
+func MatchAll() *MatchAllQuery {
+	return &MatchAllQuery{}
+}
+
+// Boost 设置权重
+func (q *MatchAllQuery) Boost(boost float64) *MatchAllQuery {
+	q.boost = &boost
+	return q
+}
+
+// Build 实现 Query
+func (q *MatchAllQuery) Build() map[string]interface{} {
+	body := make(map[string]interface{})
+	if q.boost != nil {
+		body["boost"] = *q.boost
+	}
+	return map[string]interface{}{"match_all": body}
+}
+
+// MatchNone 创建 match_none 查询（不匹配任何文档）
+func MatchNone() Query {
+	return Raw(map[string]interface{}{"match_none": map[string]interface{}{}})
+}
+
+// RawQuery 原始查询，用于尚未提供类型的查询
+type RawQuery map[string]interface{}
+
+// Raw 使用原始 DSL 创建查询
+//
+//	query.Raw(map[string]interface{}{"script": map[string]interface{}{...}})
+func Raw(dsl map[string]interface{}) RawQuery {
+	return RawQuery(dsl)
+}
+
+// Build 实现 Query
+func (q RawQuery) Build() map[string]interface{} {
+	return q
+}
@@ -0,0 +1,88 @@
+package query
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// assertJSON 比较查询构建结果与期望的 JSON
+func assertJSON(t *testing.T, q Query, expected string) {
+	t.Helper()
+
+	actual, err := json.Marshal(q.Build())
+	if err != nil {
+		t.Fatalf("序列化查询失败: %v", err)
+	}
+
+	var want, got interface{}
+	if err := json.Unmarshal([]byte(expected), &want); err != nil {
+		t.Fatalf("解析期望 JSON 失败: %v", err)
+	}
+	_ = json.Unmarshal(actual, &got)
+
+	wantJSON, _ := json.Marshal(want)
+	gotJSON, _ := json.Marshal(got)
+	if string(wantJSON) != string(gotJSON) {
+		t.Errorf("查询不一致\n期望: %s\n实际: %s", wantJSON, gotJSON)
+	}
+}
+
+// TestLeafQueries 测试单字段查询的简写与展开形式
+func TestLeafQueries(t *testing.T) {
+	assertJSON(t, Term("status", "active"), `{"term":{"status":"active"}}`)
+	assertJSON(t, Term("status", "active").Boost(2), `{"term":{"status":{"value":"active","boost":2}}}`)
+	assertJSON(t, Terms("tags", "go", "es"), `{"terms":{"tags":["go","es"]}}`)
+	assertJSON(t, Range("price").Gte(10).Lt(20), `{"range":{"price":{"gte":10,"lt":20}}}`)
+	assertJSON(t, Exists("title"), `{"exists":{"field":"title"}}`)
+	assertJSON(t, IDs("1", "2"), `{"ids":{"values":["1","2"]}}`)
+	assertJSON(t, Match("title", "golang"), `{"match":{"title":"golang"}}`)
+	assertJSON(t, Match("title", "golang es").Operator("and"), `{"match":{"title":{"query":"golang es","operator":"and"}}}`)
+	assertJSON(t, MatchPhrase("title", "hello world").Slop(2), `{"match_phrase":{"title":{"query":"hello world","slop":2}}}`)
+	assertJSON(t, MultiMatch("go", "title^2", "content").Type("best_fields"),
+		`{"multi_match":{"query":"go","fields":["title^2","content"],"type":"best_fields"}}`)
+	assertJSON(t, Wildcard("name", "jo*").CaseInsensitive(), `{"wildcard":{"name":{"value":"jo*","case_insensitive":true}}}`)
+	assertJSON(t, Fuzzy("name", "jhon").Fuzziness("AUTO"), `{"fuzzy":{"name":{"value":"jhon","fuzziness":"AUTO"}}}`)
+	assertJSON(t, MatchAll(), `{"match_all":{}}`)
+}
+
+// TestBoolQuery 测试 bool 组合查询
+func TestBoolQuery(t *testing.T) {
+	q := Bool().
+		Must(Match("title", "elasticsearch")).
+		Filter(Term("status", "published"), Range("views").Gte(100)).
+		Should(Term("tags", "go"), Term("tags", "java")).
+		MustNot(Exists("deleted_at")).
+		MinimumShouldMatch(1)
+
+	assertJSON(t, q, `{"bool":{
+		"must":[{"match":{"title":"elasticsearch"}}],
+		"filter":[{"term":{"status":"published"}},{"range":{"views":{"gte":100}}}],
+		"should":[{"term":{"tags":"go"}},{"term":{"tags":"java"}}],
+		"must_not":[{"exists":{"field":"deleted_at"}}],
+		"minimum_should_match":1
+	}}`)
+
+	// 嵌套组合
+	nested := Nested("comments", Bool().Filter(Term("comments.author", "alice"))).ScoreMode("max")
+	assertJSON(t, Bool().Filter(nested), `{"bool":{"filter":[{"nested":{
+		"path":"comments",
+		"query":{"bool":{"filter":[{"term":{"comments.author":"alice"}}]}},
+		"score_mode":"max"
+	}}]}}`)
+
+	if !Bool().IsEmpty() {
+		t.Error("没有条件的 bool 查询应该为空")
+	}
+}
+
+// TestQueryReuse 测试同一查询多次构建结果一致，构建结果不受后续修改影响
+func TestQueryReuse(t *testing.T) {
+	r := Range("price").Gte(10)
+	first := r.Build()
+	r.Lte(20)
+
+	if _, ok := first["range"].(map[string]interface{})["price"].(map[string]interface{})["lte"]; ok {
+		t.Error("已构建的查询不应该被后续链式调用修改")
+	}
+	assertJSON(t, r, `{"range":{"price":{"gte":10,"lte":20}}}`)
+}
@@ -0,0 +1,251 @@
+package query
+
+// fieldQuery 单字段查询
+// 没有额外参数时使用简写形式 {"term": {"status": "active"}}，
+// 否则展开为 {"term": {"status": {"value": "active", "boost": 2}}}
+type fieldQuery struct {
+	kind     string
+	field    string
+	valueKey string
+	value    interface{}
+	params   map[string]interface{}
+}
+
+func newFieldQuery(kind, field, valueKey string, value interface{}) fieldQuery {
+	return fieldQuery{kind: kind, field: field, valueKey: valueKey, value: value}
+}
+
+// set 设置额外参数
+func (q *fieldQuery) set(key string, value interface{}) {
+	if q.params == nil {
+		q.params = make(map[string]interface{})
+	}
+	q.params[key] = value
+}
+
+// Build 实现 Query
+func (q *fieldQuery) Build() map[string]interface{} {
+	if len(q.params) == 0 {
+		return map[string]interface{}{
+			q.kind: map[string]interface{}{q.field: q.value},
+		}
+	}
+
+	body := map[string]interface{}{q.valueKey: q.value}
+	for k, v := range q.params {
+		body[k] = v
+	}
+	return map[string]interface{}{
+		q.kind: map[string]interface{}{q.field: body},
+	}
+}
+
+// ========== term ==========
+
+// TermQuery 精确匹配查询
+type TermQuery struct {
+	fieldQuery
+}
+
+// Term 创建 term 查询
+func Term(field string, value interface{}) *TermQuery {
+	return &TermQuery{newFieldQuery("term", field, "value", value)}
+}
+
+// Boost 设置权重
+func (q *TermQuery) Boost(boost float64) *TermQuery {
+	q.set("boost", boost)
+	return q
+}
+
+// CaseInsensitive 忽略大小写匹配
+func (q *TermQuery) CaseInsensitive() *TermQuery {
+	q.set("case_insensitive", true)
+	return q
+}
+
+// ========== terms ==========
+
+// TermsQuery 多值精确匹配查询
+type TermsQuery struct {
+	field  string
+	values []interface{}
+	boost  *float64
+}
+
+// Terms 创建 terms 查询
+func Terms(field string, values ...interface{}) *TermsQuery {
+	return &TermsQuery{field: field, values: values}
+}
+
+// Boost 设置权重
+func (q *TermsQuery) Boost(boost float64) *TermsQuery {
+	q.boost = &boost
+	return q
+}
+
+// Build 实现 Query
+func (q *TermsQuery) Build() map[string]interface{} {
+	body := map[string]interface{}{q.field: q.values}
+	if q.boost != nil {
+		body["boost"] = *q.boost
+	}
+	return map[string]interface{}{"terms": body}
+}
+
+// ========== range ==========
+
+// RangeQuery 范围查询
+type RangeQuery struct {
+	field  string
+	params map[string]interface{}
+}
+
+// Range 创建范围查询
+//
+//	query.Range("price").Gte(100).Lt(200)
+//	query.Range("created_at").Gte("now-7d/d").Format("strict_date_optional_time")
+func Range(field string) *RangeQuery {
+	return &RangeQuery{field: field, params: make(map[string]interface{})}
+}
+
+// Gt 大于
+func (q *RangeQuery) Gt(value interface{}) *RangeQuery {
+	q.params["gt"] = value
+	return q
+}
+
+// Gte 大于等于
+func (q *RangeQuery) Gte(value interface{}) *RangeQuery {
+	q.params["gte"] = value
+	return q
+}
+
+// Lt 小于
+func (q *RangeQuery) Lt(value interface{}) *RangeQuery {
+	q.params["lt"] = value
+	return q
+}
+
+// Lte 小于等于
+func (q *RangeQuery) Lte(value interface{}) *RangeQuery {
+	q.params["lte"] = value
+	return q
+}
+
+// Format 设置日期格式
+func (q *RangeQuery) Format(format string) *RangeQuery {
+	q.params["format"] = format
+	return q
+}
+
+// TimeZone 设置时区，如 "+08:00"
+func (q *RangeQuery) TimeZone(timeZone string) *RangeQuery {
+	q.params["time_zone"] = timeZone
+	return q
+}
+
+// Boost 设置权重
+func (q *RangeQuery) Boost(boost float64) *RangeQuery {
+	q.params["boost"] = boost
+	return q
+}
+
+// Build 实现 Query
+func (q *RangeQuery) Build() map[string]interface{} {
+	params := make(map[string]interface{}, len(q.params))
+	for k, v := range q.params {
+		params[k] = v
+	}
+	return map[string]interface{}{
+		"range": map[string]interface{}{q.field: params},
+	}
+}
+
+// ========== exists / ids ==========
+
+// Exists 创建字段存在查询
+func Exists(field string) Query {
+	return Raw(map[string]interface{}{
+		"exists": map[string]interface{}{"field": field},
+	})
+}
+
+// IDs 创建按 ID 查询
+func IDs(ids ...string) Query {
+	return Raw(map[string]interface{}{
+		"ids": map[string]interface{}{"values": ids},
+	})
+}
+
+// ========== prefix / wildcard / regexp / fuzzy ==========
+
+// PrefixQuery 前缀查询
+type PrefixQuery struct {
+	fieldQuery
+}
+
+// Prefix 创建前缀查询
+func Prefix(field, value string) *PrefixQuery {
+	return &PrefixQuery{newFieldQuery("prefix", field, "value", value)}
+}
+
+// CaseInsensitive 忽略大小写匹配
+func (q *PrefixQuery) CaseInsensitive() *PrefixQuery {
+	q.set("case_insensitive", true)
+	return q
+}
+
+// WildcardQuery 通配符查询
+type WildcardQuery struct {
+	fieldQuery
+}
+
+// Wildcard 创建通配符查询，* 匹配任意字符，? 匹配单个字符
+func Wildcard(field, value string) *WildcardQuery {
+	return &WildcardQuery{newFieldQuery("wildcard", field, "value", value)}
+}
+
+// CaseInsensitive 忽略大小写匹配
+func (q *WildcardQuery) CaseInsensitive() *WildcardQuery {
+	q.set("case_insensitive", true)
+	return q
+}
+
+// Boost 设置权重
+func (q *WildcardQuery) Boost(boost float64) *WildcardQuery {
+	q.set("boost", boost)
+	return q
+}
+
+// RegexpQuery 正则查询
+type RegexpQuery struct {
+	fieldQuery
+}
+
+// Regexp 创建正则查询
+func Regexp(field, value string) *RegexpQuery {
+	return &RegexpQuery{newFieldQuery("regexp", field, "value", value)}
+}
+
+// Flags 设置正则特性，如 "ALL"、"INTERSECTION|COMPLEMENT"
+func (q *RegexpQuery) Flags(flags string) *RegexpQuery {
+	q.set("flags", flags)
+	return q
+}
+
+// FuzzyQuery 模糊查询
+type FuzzyQuery struct {
+	fieldQuery
+}
+
+// Fuzzy 创建模糊查询
+func Fuzzy(field string, value interface{}) *FuzzyQuery {
+	return &FuzzyQuery{newFieldQuery("fuzzy", field, "value", value)}
+}
+
+// Fuzziness 设置允许的编辑距离，如 "AUTO"、1、2
+func (q *FuzzyQuery) Fuzziness(fuzziness interface{}) *FuzzyQuery {
+	q.set("fuzziness", fuzziness)
+	return q
+}
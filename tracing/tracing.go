@@ -0,0 +1,185 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// 标准属性名（参考 OpenTelemetry 数据库语义约定）
+const (
+	AttrDBSystem          = "db.system"
+	AttrDBOperation       = "db.operation"
+	AttrDBStatement       = "db.statement"
+	AttrIndex             = "db.elasticsearch.path_parts.index"
+	AttrHTTPMethod        = "http.request.method"
+	AttrHTTPStatusCode    = "http.response.status_code"
+	AttrURLPath           = "url.path"
+	AttrURLFull           = "url.full"
+	AttrServerAddress     = "server.address"
+	AttrRetryCount        = "http.request.resend_count"
+	DBSystemElasticsearch = "elasticsearch"
+)
+
+// Attribute span 属性
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Attr 创建 span 属性
+func Attr(key string, value interface{}) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Tracer 链路追踪接口，可以通过适配器对接 OpenTelemetry 等实现
+type Tracer interface {
+	// Start 创建 span，返回的 context 携带该 span
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span 链路追踪 span
+type Span interface {
+	// SetAttributes 设置属性
+	SetAttributes(attrs ...Attribute)
+
+	// RecordError 记录错误并将 span 标记为失败
+	RecordError(err error)
+
+	// End 结束 span
+	End()
+
+	// TraceParent 返回 W3C traceparent 头的值，返回空字符串时不向 ES 传播
+	TraceParent() string
+}
+
+// FormatTraceParent 按 W3C Trace Context 格式生成 traceparent
+func FormatTraceParent(traceID, spanID string, sampled bool) string {
+	flags := "00"
+	if sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", traceID, spanID, flags)
+}
+
+// ========== 内存记录器（用于测试） ==========
+
+// RecordedSpan 记录的 span
+type RecordedSpan struct {
+	Name         string
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	Attributes   map[string]interface{}
+	Err          error
+	StartTime    time.Time
+	EndTime      time.Time
+}
+
+// Duration span 耗时
+func (s RecordedSpan) Duration() time.Duration {
+	return s.EndTime.Sub(s.StartTime)
+}
+
+// Recorder 内存链路追踪记录器，记录所有已结束的 span
+type Recorder struct {
+	mu    sync.Mutex
+	spans []RecordedSpan
+}
+
+// NewRecorder 创建内存记录器
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+type recorderSpanKey struct{}
+
+// Start 实现 Tracer
+func (r *Recorder) Start(ctx context.Context, name string) (context.Context, Span) {
+	span := &recorderSpan{
+		recorder: r,
+		data: RecordedSpan{
+			Name:       name,
+			SpanID:     randomHex(8),
+			Attributes: make(map[string]interface{}),
+			StartTime:  time.Now(),
+		},
+	}
+	if parent, ok := ctx.Value(recorderSpanKey{}).(*recorderSpan); ok {
+		span.data.TraceID = parent.data.TraceID
+		span.data.ParentSpanID = parent.data.SpanID
+	} else {
+		span.data.TraceID = randomHex(16)
+	}
+	return context.WithValue(ctx, recorderSpanKey{}, span), span
+}
+
+// Spans 返回已结束的 span
+func (r *Recorder) Spans() []RecordedSpan {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RecordedSpan(nil), r.spans...)
+}
+
+// Reset 清空记录
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = nil
+}
+
+// recorderSpan 内存记录器的 span
+type recorderSpan struct {
+	recorder *Recorder
+	mu       sync.Mutex
+	data     RecordedSpan
+	ended    bool
+}
+
+// SetAttributes 实现 Span
+func (s *recorderSpan) SetAttributes(attrs ...Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, attr := range attrs {
+		s.data.Attributes[attr.Key] = attr.Value
+	}
+}
+
+// RecordError 实现 Span
+func (s *recorderSpan) RecordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Err = err
+}
+
+// End 实现 Span
+func (s *recorderSpan) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.EndTime = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	s.recorder.mu.Lock()
+	s.recorder.spans = append(s.recorder.spans, data)
+	s.recorder.mu.Unlock()
+}
+
+// TraceParent 实现 Span
+func (s *recorderSpan) TraceParent() string {
+	return FormatTraceParent(s.data.TraceID, s.data.SpanID, true)
+}
+
+// randomHex 生成 n 字节的随机十六进制字符串
+func randomHex(n int) string {
+	buf := make([]byte, n)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,60 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFormatTraceParent(t *testing.T) {
+	traceID := "4bf92f3577b34da6a3ce929d0e0e4736"
+	spanID := "00f067aa0ba902b7"
+
+	if got := FormatTraceParent(traceID, spanID, true); got != "00-"+traceID+"-"+spanID+"-01" {
+		t.Errorf("采样的 traceparent 不正确: %s", got)
+	}
+	if got := FormatTraceParent(traceID, spanID, false); got != "00-"+traceID+"-"+spanID+"-00" {
+		t.Errorf("未采样的 traceparent 不正确: %s", got)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	r := NewRecorder()
+
+	ctx, parent := r.Start(context.Background(), "parent")
+	_, child := r.Start(ctx, "child")
+	child.SetAttributes(Attr("k", "v"))
+	child.RecordError(errors.New("boom"))
+	child.End()
+	child.End() // 重复结束只记录一次
+
+	if len(r.Spans()) != 1 {
+		t.Fatalf("只应该记录已结束的 span，实际 %d", len(r.Spans()))
+	}
+	parent.End()
+
+	spans := r.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("应该记录 2 个 span，实际 %d", len(spans))
+	}
+	c, p := spans[0], spans[1]
+	if len(p.TraceID) != 32 || len(p.SpanID) != 16 || p.ParentSpanID != "" {
+		t.Fatalf("根 span 的 ID 不正确: %+v", p)
+	}
+	if c.TraceID != p.TraceID || c.ParentSpanID != p.SpanID || c.SpanID == p.SpanID {
+		t.Fatalf("子 span 应该继承 trace ID 并指向父 span: %+v", c)
+	}
+	if c.Attributes["k"] != "v" || c.Err == nil || c.Duration() < 0 {
+		t.Fatalf("子 span 的属性/错误不正确: %+v", c)
+	}
+	if child.TraceParent() != FormatTraceParent(c.TraceID, c.SpanID, true) {
+		t.Fatalf("TraceParent 不正确: %s", child.TraceParent())
+	}
+
+	r.Reset()
+	if len(r.Spans()) != 0 {
+		t.Fatal("Reset 后应该清空记录")
+	}
+
+	t.Logf("✓ 内存记录器")
+}